@@ -0,0 +1,87 @@
+package ch
+
+import (
+	"fmt"
+	"mysql_to_md/common"
+)
+
+const (
+	// CHSqlSchemaColumn 批量查看数据库所有数据表列信息SQL-clickhouse
+	CHSqlSchemaColumn = "SELECT `table`," + chColumnFields + " from system.columns where database = '%s'%s order by `table`,`position`"
+)
+
+// Prefetch 一次查询整个库的列信息、存储结构、跳数索引及数据量统计并按表分组，替代逐表查询
+func (c *Clickhouse) Prefetch(tables []common.TableInfo) error {
+	columns, err := c.querySchemaColumn(tableFilter("`table`", c.Conf, tables))
+	if err != nil {
+		return err
+	}
+	c.columns = columns
+
+	storages, err := c.queryStorage(tableFilter("name", c.Conf, tables))
+	if err != nil {
+		return err
+	}
+	// tables without storage are known as well, no need to query them one by one
+	for _, table := range tables {
+		if _, ok := storages[table.Name]; !ok {
+			storages[table.Name] = nil
+		}
+	}
+	c.storages = storages
+
+	// index lookup failure is not fatal, and the tables are not queried again one by one
+	indexes, err := c.queryIndex(tableFilter("`table`", c.Conf, tables))
+	if err != nil {
+		c.Conf.Logf("execute query table index error,had ignored, detail is [%v]\n", err.Error())
+		indexes = make(map[string][]common.TableIndex)
+	}
+	c.indexes = indexes
+
+	if c.Conf.Stats {
+		stats, err := c.queryStats(tableFilter("`table`", c.Conf, tables))
+		if err != nil {
+			return err
+		}
+		// tables without active parts are empty
+		for _, table := range tables {
+			if _, ok := stats[table.Name]; !ok {
+				stats[table.Name] = nil
+			}
+		}
+		c.stats = stats
+	}
+	return nil
+}
+
+// tableFilter 指定了表过滤时只查询选中的表，field为表名字段
+func tableFilter(field string, conf *common.Conf, tables []common.TableInfo) string {
+	if conf.Tables == "" {
+		return ""
+	}
+	names := make([]string, 0, len(tables))
+	for _, table := range tables {
+		names = append(names, table.Name)
+	}
+	return " and " + field + " in (" + common.QuoteList(names) + ")"
+}
+
+// querySchemaColumn 批量查询列信息，filter为附加的过滤条件
+func (c *Clickhouse) querySchemaColumn(filter string) (map[string][]common.TableColumn, error) {
+	rows, err := c.DB.Raw(fmt.Sprintf(CHSqlSchemaColumn, c.Conf.Database, filter)).Rows()
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	columns := make(map[string][]common.TableColumn)
+	for rows.Next() {
+		var tableName string
+		var column common.TableColumn
+		err = rows.Scan(append([]interface{}{&tableName}, columnDest(&column)...)...)
+		if err != nil {
+			return nil, err
+		}
+		columns[tableName] = append(columns[tableName], column)
+	}
+	return columns, rows.Err()
+}
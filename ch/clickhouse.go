@@ -1,11 +1,9 @@
 package ch
 
 import (
-	"database/sql"
 	"fmt"
 	"mysql_to_md/common"
 	"regexp"
-	"strings"
 
 	"gorm.io/gorm"
 )
@@ -14,17 +12,38 @@ import (
 type Clickhouse struct {
 	DB   *gorm.DB
 	Conf *common.Conf
+
+	// columns 批量查询的列信息，key为表名
+	columns map[string][]common.TableColumn
+	// kinds 表的对象类型，key为表名
+	kinds map[string]common.TableKind
+	// storages 批量查询的存储结构，key为表名
+	storages map[string]*common.StorageLayout
+	// stats 批量查询的数据量统计，key为表名
+	stats map[string]*common.TableStats
+	// indexes 批量查询的跳数索引，key为表名，批量查询后为非nil
+	indexes map[string][]common.TableIndex
+	// clusters 集群拓扑，开启集群信息时查询
+	clusters []common.Cluster
 }
 
 const (
-	// CHSqlTables 查看数据库所有数据表SQL-clickhouse
-	CHSqlTables = "SELECT  `table` as table_name,'' as table_comment from system.parts where database ='%s' group by table_name"
+	// CHSqlTables 查看数据库所有数据表SQL-clickhouse，包含没有数据的表、视图及字典
+	CHSqlTables = "SELECT name as table_name,comment as table_comment,engine from system.tables where database ='%s' and is_temporary = 0 and not startsWith(name, '.inner') order by table_name"
 	// CHSqlTableColumn 查看数据表列信息SQL-clickhouse
-	CHSqlTableColumn = "SELECT  `position` as ORDINAL_POSITION,name as COLUMN_NAME,type as COLUMN_TYPE,is_in_partition_key as COLUMN_KEY, '' as IS_NULLABLE,comment  as COLUMN_COMMENT,default_expression as COLUMN_DEFAULT from system.columns where database = '%s' and table = '%s'"
+	CHSqlTableColumn = "SELECT " + chColumnFields + " from system.columns where database = '%s' and table = '%s'"
 	// SqlTableCreate 查看建表语句
 	SqlTableCreate = "SHOW CREATE TABLE %s"
+	// SqlDictionaryCreate 查看字典创建语句
+	SqlDictionaryCreate = "SHOW CREATE DICTIONARY %s"
+	// CHSqlDictionaryNames 查看数据库中的字典，Dictionary 引擎的普通表不在其中
+	CHSqlDictionaryNames = "SELECT name from system.dictionaries where database = '%s'"
 )
 
+// chColumnFields 列信息查询字段，与 columnDest 的顺序一致
+const chColumnFields = "`position` as ORDINAL_POSITION,name as COLUMN_NAME,type as COLUMN_TYPE,is_in_partition_key as COLUMN_KEY, '' as IS_NULLABLE,comment  as COLUMN_COMMENT,default_expression as COLUMN_DEFAULT," +
+	"is_in_sorting_key,is_in_primary_key,compression_codec,data_compressed_bytes,data_uncompressed_bytes"
+
 type chTableCreateSql struct {
 	CreateSql string `db:"statement"`
 }
@@ -32,50 +51,41 @@ type chTableCreateSql struct {
 // QueryTables 查询所有表
 func (c *Clickhouse) QueryTables() ([]common.TableInfo, error) {
 	var tableCollect []common.TableInfo
-	var tableArray []string
-	var commentArray []sql.NullString
 
 	querySql := CHSqlTables
 	rows, err := c.DB.Raw(fmt.Sprintf(querySql, c.Conf.Database)).Rows()
 	if err != nil {
 		return tableCollect, err
 	}
+	defer rows.Close()
 
+	c.kinds = make(map[string]common.TableKind)
 	for rows.Next() {
 		var info common.TableInfo
-		err = rows.Scan(&info.Name, &info.Comment)
+		err = rows.Scan(&info.Name, &info.Comment, &info.Engine)
 		if err != nil {
-			fmt.Printf("execute query tables action error,had ignored, detail is [%v]\n", err.Error())
+			c.Conf.Logf("execute query tables action error,had ignored, detail is [%v]\n", err.Error())
 			continue
 		}
+		info.Kind = kindOfEngine(info.Engine)
+		c.kinds[info.Name] = info.Kind
 
 		tableCollect = append(tableCollect, info)
-		tableArray = append(tableArray, info.Name)
-		commentArray = append(commentArray, info.Comment)
+	}
+	// tables created with ENGINE = Dictionary(d) are tables, only objects in system.dictionaries are dictionaries
+	dictionaries, dictionaryErr := c.queryDictionaryNames()
+	if dictionaryErr != nil {
+		c.Conf.Logf("execute query dictionaries error,had ignored, detail is [%v]\n", dictionaryErr.Error())
+	}
+	for i := range tableCollect {
+		if tableCollect[i].Kind == common.KindDictionary && dictionaries != nil && !dictionaries[tableCollect[i].Name] {
+			tableCollect[i].Kind = common.KindTable
+			c.kinds[tableCollect[i].Name] = common.KindTable
+		}
 	}
 	// filter tables when specified tables params
 	if c.Conf.Tables != "" {
-		tableCollect = nil
-		chooseTables := strings.Split(c.Conf.Tables, ",")
-		indexMap := make(map[int]int)
-		for _, item := range chooseTables {
-			subIndexMap := common.GetTargetIndexMap(tableArray, item)
-			for k, v := range subIndexMap {
-				if _, ok := indexMap[k]; ok {
-					continue
-				}
-				indexMap[k] = v
-			}
-		}
-
-		if len(indexMap) != 0 {
-			for _, v := range indexMap {
-				var info common.TableInfo
-				info.Name = tableArray[v]
-				info.Comment = commentArray[v]
-				tableCollect = append(tableCollect, info)
-			}
-		}
+		tableCollect = common.FilterTables(tableCollect, c.Conf.Tables)
 	}
 
 	return tableCollect, err
@@ -83,6 +93,10 @@ func (c *Clickhouse) QueryTables() ([]common.TableInfo, error) {
 
 // QueryTableColumn 查询表字段
 func (c *Clickhouse) QueryTableColumn(tableName string) ([]common.TableColumn, error) {
+	// 优先使用批量查询结果
+	if columns, ok := c.columns[tableName]; ok {
+		return columns, nil
+	}
 	// 定义承载列信息的切片
 	var columns []common.TableColumn
 
@@ -90,21 +104,15 @@ func (c *Clickhouse) QueryTableColumn(tableName string) ([]common.TableColumn, e
 
 	rows, err := c.DB.Raw(fmt.Sprintf(querySql, c.Conf.Database, tableName)).Rows()
 	if err != nil {
-		fmt.Printf("execute query table column action error, detail is [%v]\n", err.Error())
+		c.Conf.Logf("execute query table column action error, detail is [%v]\n", err.Error())
 		return columns, err
 	}
+	defer rows.Close()
 	for rows.Next() {
 		var column common.TableColumn
-		err = rows.Scan(
-			&column.OrdinalPosition,
-			&column.ColumnName,
-			&column.ColumnType,
-			&column.ColumnKey,
-			&column.IsNullable,
-			&column.ColumnComment,
-			&column.ColumnDefault)
+		err = rows.Scan(columnDest(&column)...)
 		if err != nil {
-			fmt.Printf("query table column scan error, detail is [%v]\n", err.Error())
+			c.Conf.Logf("query table column scan error, detail is [%v]\n", err.Error())
 			return columns, err
 		}
 		columns = append(columns, column)
@@ -117,15 +125,110 @@ func (c *Clickhouse) QueryTableColumn(tableName string) ([]common.TableColumn, e
 func (c *Clickhouse) QueryCreateSql(tableName string) (string, error) {
 	var createSql chTableCreateSql
 	var err error
-	rows, err := c.DB.Raw(fmt.Sprintf(SqlTableCreate, tableName)).Rows()
-	for rows.Next() {
-		rows.Scan(&createSql.CreateSql)
+	querySql := SqlTableCreate
+	if c.kinds[tableName] == common.KindDictionary {
+		querySql = SqlDictionaryCreate
+	}
+	rows, err := c.DB.Raw(fmt.Sprintf(querySql, tableName)).Rows()
+	if err != nil && querySql == SqlDictionaryCreate {
+		// system.dictionaries is not readable, the object may be a table with Dictionary engine
+		c.Conf.Logf("execute query dictionary create sql error, query as table, detail is [%v]\n", err.Error())
+		rows, err = c.DB.Raw(fmt.Sprintf(SqlTableCreate, tableName)).Rows()
 	}
 	if err != nil {
-		fmt.Printf("execute query table create sql error, detail is [%v]\n", err.Error())
+		c.Conf.Logf("execute query table create sql error, detail is [%v]\n", err.Error())
 		return "", err
 	}
+	defer rows.Close()
+	for rows.Next() {
+		rows.Scan(&createSql.CreateSql)
+	}
 	reg := regexp.MustCompile(`AUTO_INCREMENT=\d+ `)
 	res := reg.ReplaceAllString(createSql.CreateSql, "")
 	return res, nil
 }
+
+// columnDest 与 chColumnFields 顺序一致的列信息扫描目标
+func columnDest(column *common.TableColumn) []interface{} {
+	return []interface{}{
+		&column.OrdinalPosition,
+		&column.ColumnName,
+		&column.ColumnType,
+		&column.ColumnKey,
+		&column.IsNullable,
+		&column.ColumnComment,
+		&column.ColumnDefault,
+		&column.InSortingKey,
+		&column.InPrimaryKey,
+		&column.Codec,
+		&column.CompressedBytes,
+		&column.UncompressedBytes,
+	}
+}
+
+// ExtendTable 补充表的存储结构、字段TTL、索引、数据量统计、字典及分布式表信息
+func (c *Clickhouse) ExtendTable(table *common.Table) error {
+	parseColumnTTL(table.CreateSql, table.Columns)
+	if err := c.extendStorage(table); err != nil {
+		return err
+	}
+	if err := c.extendIndex(table); err != nil {
+		return err
+	}
+	if table.Kind == common.KindDictionary && dictionaryReg.MatchString(table.CreateSql) {
+		if err := c.extendDictionary(table); err != nil {
+			return err
+		}
+	}
+	if c.Conf.Stats {
+		if err := c.extendStats(table); err != nil {
+			return err
+		}
+	}
+	if table.Kind == common.KindDistributed {
+		if err := c.extendDistributed(table); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// queryDictionaryNames 查询数据库中所有字典的名称
+func (c *Clickhouse) queryDictionaryNames() (map[string]bool, error) {
+	rows, err := c.DB.Raw(fmt.Sprintf(CHSqlDictionaryNames, c.Conf.Database)).Rows()
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	names := make(map[string]bool)
+	for rows.Next() {
+		var name string
+		if err = rows.Scan(&name); err != nil {
+			return nil, err
+		}
+		names[name] = true
+	}
+	return names, rows.Err()
+}
+
+// dictionaryReg 字典的创建语句，Dictionary 引擎的表为 CREATE TABLE
+var dictionaryReg = regexp.MustCompile(`(?i)^\s*CREATE\s+(OR\s+REPLACE\s+)?DICTIONARY\b`)
+
+// kindOfEngine 根据表引擎判断对象类型，Dictionary 引擎的普通表由 QueryTables 按 system.dictionaries 改为表
+func kindOfEngine(engine string) common.TableKind {
+	switch engine {
+	case "View", "LiveView", "WindowView":
+		return common.KindView
+	case "MaterializedView":
+		return common.KindMaterializedView
+	case "Distributed":
+		return common.KindDistributed
+	case "Merge":
+		return common.KindMerge
+	case "Kafka":
+		return common.KindKafka
+	case "Dictionary":
+		return common.KindDictionary
+	}
+	return common.KindTable
+}
@@ -0,0 +1,16 @@
+package ch
+
+import "testing"
+
+func TestDictionaryReg(t *testing.T) {
+	cases := map[string]bool{
+		"CREATE DICTIONARY test.cities (`id` UInt64, `name` String) PRIMARY KEY id SOURCE(CLICKHOUSE(TABLE 'city')) LAYOUT(FLAT()) LIFETIME(300)": true,
+		"create or replace dictionary test.cities (`id` UInt64) PRIMARY KEY id":                                                                   true,
+		"CREATE TABLE test.city_dict (`id` UInt64, `name` String) ENGINE = Dictionary(test.cities)":                                               false,
+	}
+	for createSql, dictionary := range cases {
+		if dictionaryReg.MatchString(createSql) != dictionary {
+			t.Errorf("dictionaryReg.MatchString(%q) = %v, want %v", createSql, !dictionary, dictionary)
+		}
+	}
+}
@@ -0,0 +1,231 @@
+package ch
+
+import (
+	"fmt"
+	"mysql_to_md/common"
+	"sort"
+	"strings"
+)
+
+const (
+	// CHSqlClusters 查看集群拓扑SQL-clickhouse
+	CHSqlClusters = "SELECT cluster,shard_num,replica_num,host_name,port from system.clusters order by cluster,shard_num,replica_num"
+	// CHSqlReplicaColumns 查看本地表在集群所有副本上的列信息SQL-clickhouse
+	CHSqlReplicaColumns = "SELECT hostName() as host,name,type from clusterAllReplicas('%s', system.columns) where database = '%s' and table = '%s' order by host,position"
+)
+
+// ExtendSchema 补充表之间的数据流向及集群拓扑
+func (c *Clickhouse) ExtendSchema(schema *common.Schema) error {
+	if err := c.queryLineage(schema); err != nil {
+		return err
+	}
+	if c.Conf.Cluster || c.Conf.VerifyReplicas {
+		clusters, err := c.queryClusters()
+		if err != nil {
+			c.Conf.Logf("execute query clusters error, detail is [%v]\n", err.Error())
+			return err
+		}
+		schema.Clusters = clusters
+		c.clusters = clusters
+	}
+	return nil
+}
+
+// queryClusters 查询集群的分片、副本及节点
+func (c *Clickhouse) queryClusters() ([]common.Cluster, error) {
+	rows, err := c.DB.Raw(CHSqlClusters).Rows()
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var clusters []common.Cluster
+	for rows.Next() {
+		var name, host string
+		var shardNum, replicaNum uint32
+		var port uint16
+		if err = rows.Scan(&name, &shardNum, &replicaNum, &host, &port); err != nil {
+			return nil, err
+		}
+		if len(clusters) == 0 || clusters[len(clusters)-1].Name != name {
+			clusters = append(clusters, common.Cluster{Name: name})
+		}
+		cluster := &clusters[len(clusters)-1]
+		if int(shardNum) > cluster.Shards {
+			cluster.Shards = int(shardNum)
+		}
+		if int(replicaNum) > cluster.Replicas {
+			cluster.Replicas = int(replicaNum)
+		}
+		cluster.Hosts = append(cluster.Hosts, fmt.Sprintf("%s:%d", host, port))
+	}
+	return clusters, rows.Err()
+}
+
+// extendDistributed 补充分布式表的集群、本地表及分片键，开启校验时对比各副本上本地表的结构
+func (c *Clickhouse) extendDistributed(table *common.Table) error {
+	if table.Storage == nil {
+		return nil
+	}
+	table.Distributed = c.parseDistributed(table.Storage.EngineFull)
+	if table.Distributed == nil || !c.Conf.VerifyReplicas {
+		return nil
+	}
+	// a replica that can not be queried is reported on the table instead of stopping the run
+	drift, err := c.verifyReplicas(table.Distributed)
+	if err != nil {
+		c.Conf.Logf("execute verify replicas error,had ignored, detail is [%v]\n", err.Error())
+		drift = []string{"校验副本失败：" + strings.ReplaceAll(err.Error(), "\n", " ")}
+	}
+	table.Distributed.Drift = drift
+	return nil
+}
+
+// parseDistributed 解析 Distributed(cluster, database, table[, sharding_key[, policy_name]]) 引擎参数
+func (c *Clickhouse) parseDistributed(engineFull string) *common.DistributedTable {
+	args := engineArgs(engineFull, "Distributed")
+	if len(args) < 3 {
+		return nil
+	}
+	database := unquote(args[1])
+	if strings.HasPrefix(database, "currentDatabase(") {
+		database = c.Conf.Database
+	}
+	distributed := &common.DistributedTable{
+		Cluster:    unquote(args[0]),
+		LocalTable: c.tableName(database, unquote(args[2])),
+	}
+	if len(args) > 3 {
+		distributed.ShardingKey = args[3]
+	}
+	return distributed
+}
+
+// verifyReplicas 对比本地表在所有副本上的列，以多数副本的结构为准返回差异
+func (c *Clickhouse) verifyReplicas(distributed *common.DistributedTable) ([]string, error) {
+	database, localTable := c.Conf.Database, distributed.LocalTable
+	if i := strings.Index(localTable, "."); i >= 0 {
+		database, localTable = localTable[:i], localTable[i+1:]
+	}
+	rows, err := c.DB.Raw(fmt.Sprintf(CHSqlReplicaColumns, distributed.Cluster, database, localTable)).Rows()
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var hosts []string
+	columns := make(map[string][]replicaColumn)
+	for rows.Next() {
+		var host string
+		var column replicaColumn
+		if err = rows.Scan(&host, &column.name, &column.columnType); err != nil {
+			return nil, err
+		}
+		if _, ok := columns[host]; !ok {
+			hosts = append(hosts, host)
+		}
+		columns[host] = append(columns[host], column)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	// the structure shared by most replicas is the reference
+	counts := make(map[string]int)
+	var reference []replicaColumn
+	for _, host := range hosts {
+		signature := columnSignature(columns[host])
+		counts[signature]++
+		if counts[signature] > counts[columnSignature(reference)] {
+			reference = columns[host]
+		}
+	}
+	var drift []string
+	for _, host := range hosts {
+		if columnSignature(columns[host]) != columnSignature(reference) {
+			drift = append(drift, host+": "+diffColumns(reference, columns[host]))
+		}
+	}
+	sort.Strings(drift)
+	for _, cluster := range c.clusters {
+		if cluster.Name == distributed.Cluster && len(hosts) < len(cluster.Hosts) {
+			drift = append(drift, fmt.Sprintf("仅 %d/%d 个副本存在本地表", len(hosts), len(cluster.Hosts)))
+		}
+	}
+	return drift, nil
+}
+
+// replicaColumn 副本上本地表的一列
+type replicaColumn struct {
+	name       string
+	columnType string
+}
+
+// columnSignature 按顺序拼接的列，用于比较副本结构是否一致，
+// 名称及类型中可能含有逗号、空格，如 Decimal(18, 4)，以换行分隔
+func columnSignature(columns []replicaColumn) string {
+	var signature strings.Builder
+	for _, column := range columns {
+		signature.WriteString(column.name + "\t" + column.columnType + "\n")
+	}
+	return signature.String()
+}
+
+// diffColumns 逐列对比副本与参照副本的列
+func diffColumns(reference, columns []replicaColumn) string {
+	types := make(map[string]string)
+	for _, column := range columns {
+		types[column.name] = column.columnType
+	}
+	var diffs []string
+	referenced := make(map[string]bool)
+	for _, column := range reference {
+		referenced[column.name] = true
+		if actual, ok := types[column.name]; !ok {
+			diffs = append(diffs, "缺少字段 "+column.name)
+		} else if actual != column.columnType {
+			diffs = append(diffs, "字段 "+column.name+" 类型为 "+actual+"，应为 "+column.columnType)
+		}
+	}
+	for _, column := range columns {
+		if !referenced[column.name] {
+			diffs = append(diffs, "多出字段 "+column.name)
+		}
+	}
+	if len(diffs) == 0 {
+		return "字段顺序不一致"
+	}
+	return strings.Join(diffs, "；")
+}
+
+// engineArgs 按顶层逗号拆分 engine(args...) 的参数
+func engineArgs(engineFull, engine string) []string {
+	if !strings.HasPrefix(engineFull, engine+"(") {
+		return nil
+	}
+	body := engineFull[len(engine)+1:]
+	var args []string
+	var depth, start int
+	var quoted bool
+	for i := 0; i < len(body); i++ {
+		switch ch := body[i]; {
+		case ch == '\'' && (i == 0 || body[i-1] != '\\'):
+			quoted = !quoted
+		case quoted:
+		case ch == '(':
+			depth++
+		case ch == ',' && depth == 0:
+			args = append(args, strings.TrimSpace(body[start:i]))
+			start = i + 1
+		case ch == ')':
+			if depth == 0 {
+				return append(args, strings.TrimSpace(body[start:i]))
+			}
+			depth--
+		}
+	}
+	return args
+}
+
+// unquote 去掉引擎参数的空格及引号
+func unquote(s string) string {
+	return strings.Trim(strings.TrimSpace(s), "'\"`")
+}
@@ -0,0 +1,42 @@
+package ch
+
+import "testing"
+
+func TestDiffColumns(t *testing.T) {
+	reference := []replicaColumn{
+		{"id", "UInt64"},
+		{"amount", "Decimal(18, 4)"},
+		{"attrs", "Map(String, Tuple(a UInt8, b String))"},
+	}
+	cases := []struct {
+		name    string
+		columns []replicaColumn
+		diff    string
+	}{
+		{
+			name:    "type changed",
+			columns: []replicaColumn{{"id", "UInt64"}, {"amount", "Decimal(18, 2)"}, {"attrs", "Map(String, Tuple(a UInt8, b String))"}},
+			diff:    "字段 amount 类型为 Decimal(18, 2)，应为 Decimal(18, 4)",
+		},
+		{
+			name:    "missing and extra",
+			columns: []replicaColumn{{"id", "UInt64"}, {"amount", "Decimal(18, 4)"}, {"extra", "String"}},
+			diff:    "缺少字段 attrs；多出字段 extra",
+		},
+		{
+			name:    "order",
+			columns: []replicaColumn{{"amount", "Decimal(18, 4)"}, {"id", "UInt64"}, {"attrs", "Map(String, Tuple(a UInt8, b String))"}},
+			diff:    "字段顺序不一致",
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			if columnSignature(c.columns) == columnSignature(reference) {
+				t.Fatal("signature should differ from reference")
+			}
+			if diff := diffColumns(reference, c.columns); diff != c.diff {
+				t.Errorf("diffColumns = %q, want %q", diff, c.diff)
+			}
+		})
+	}
+}
@@ -0,0 +1,47 @@
+package ch
+
+import (
+	"context"
+	"mysql_to_md/common"
+
+	"gorm.io/driver/clickhouse"
+	"gorm.io/gorm"
+)
+
+func init() {
+	common.RegisterDialect(common.Dialect{
+		Name:        "clickhouse",
+		Description: "ClickHouse",
+		BuildDSN:    BuildDSN,
+		NewHandler:  New,
+		Capabilities: common.Capabilities{
+			Comments: true,
+		},
+	})
+}
+
+// New 连接clickhouse并创建Clickhouse
+func New(ctx context.Context, conf *common.Conf, dsn string) (common.Handler, error) {
+	db, err := gorm.Open(clickhouse.Open(dsn), &gorm.Config{})
+	if err != nil {
+		return nil, err
+	}
+	// keep enough idle connections for concurrent metadata queries
+	sqlDB, err := db.DB()
+	if err != nil {
+		return nil, err
+	}
+	if conf.Concurrency > 1 {
+		sqlDB.SetMaxIdleConns(conf.Concurrency)
+	}
+	return &Clickhouse{DB: db.WithContext(ctx), Conf: conf}, nil
+}
+
+// Close 关闭连接池，生成文档后由generator调用
+func (c *Clickhouse) Close() error {
+	sqlDB, err := c.DB.DB()
+	if err != nil {
+		return err
+	}
+	return sqlDB.Close()
+}
@@ -0,0 +1,56 @@
+package ch
+
+import (
+	"errors"
+	"mysql_to_md/common"
+	"net"
+	"net/url"
+	"strconv"
+
+	"github.com/ClickHouse/clickhouse-go"
+)
+
+// BuildDSN 生成clickhouse连接串，优先使用完整的dsn参数，并追加TLS配置
+func BuildDSN(conf *common.Conf) (string, error) {
+	var u *url.URL
+	if conf.DSN != "" {
+		var err error
+		if u, err = url.Parse(conf.DSN); err != nil {
+			return "", err
+		}
+	} else {
+		u = &url.URL{Scheme: "tcp", Host: net.JoinHostPort(conf.Host, strconv.Itoa(conf.Port))}
+		query := url.Values{}
+		query.Set("database", conf.Database)
+		query.Set("username", conf.Username)
+		query.Set("password", conf.Password)
+		u.RawQuery = query.Encode()
+	}
+
+	query := u.Query()
+	tlsConfig, err := common.BuildTLSConfig(conf)
+	if err != nil {
+		return "", err
+	}
+	if tlsConfig != nil {
+		name := common.TLSConfigName(conf)
+		if err = clickhouse.RegisterTLSConfig(name, tlsConfig); err != nil {
+			return "", err
+		}
+		query.Set("tls_config", name)
+		// clickhouse-go overrides InsecureSkipVerify by the skip_verify param
+		if conf.TLSSkipVerify {
+			query.Set("skip_verify", "true")
+		}
+	}
+	// database in dsn takes precedence, tables are queried by it, otherwise the database param is used
+	if query.Get("database") == "" {
+		if conf.Database == "" {
+			return "", errors.New("no database specified in dsn or database param")
+		}
+		query.Set("database", conf.Database)
+	}
+	conf.Database = query.Get("database")
+	u.RawQuery = query.Encode()
+	return u.String(), nil
+}
@@ -0,0 +1,60 @@
+package ch
+
+import (
+	"mysql_to_md/common"
+	"testing"
+)
+
+func TestBuildDSN(t *testing.T) {
+	cases := []struct {
+		name     string
+		conf     common.Conf
+		dsn      string
+		database string
+	}{
+		{
+			name:     "params",
+			conf:     common.Conf{Host: "127.0.0.1", Port: 9000, Username: "default", Password: "pw", Database: "test"},
+			dsn:      "tcp://127.0.0.1:9000?database=test&password=pw&username=default",
+			database: "test",
+		},
+		{
+			name:     "database in dsn takes precedence",
+			conf:     common.Conf{DSN: "tcp://127.0.0.1:9000?database=shop&username=u", Database: "mysql"},
+			dsn:      "tcp://127.0.0.1:9000?database=shop&username=u",
+			database: "shop",
+		},
+		{
+			name:     "database param used when dsn has none",
+			conf:     common.Conf{DSN: "tcp://127.0.0.1:9000?username=u", Database: "shop"},
+			dsn:      "tcp://127.0.0.1:9000?database=shop&username=u",
+			database: "shop",
+		},
+		{
+			name:     "tls",
+			conf:     common.Conf{DSN: "tcp://127.0.0.1:9440?database=shop", TLSSkipVerify: true},
+			dsn:      "tcp://127.0.0.1:9440?database=shop&skip_verify=true&tls_config=" + common.TLSConfigName(&common.Conf{TLSSkipVerify: true}),
+			database: "shop",
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			dsn, err := BuildDSN(&c.conf)
+			if err != nil {
+				t.Fatalf("BuildDSN error: %v", err)
+			}
+			if dsn != c.dsn {
+				t.Errorf("BuildDSN = %q, want %q", dsn, c.dsn)
+			}
+			if c.conf.Database != c.database {
+				t.Errorf("database = %q, want %q", c.conf.Database, c.database)
+			}
+		})
+	}
+}
+
+func TestBuildDSNWithoutDatabase(t *testing.T) {
+	if _, err := BuildDSN(&common.Conf{DSN: "tcp://127.0.0.1:9000?username=u"}); err == nil {
+		t.Error("BuildDSN without database should fail")
+	}
+}
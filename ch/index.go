@@ -0,0 +1,105 @@
+package ch
+
+import (
+	"fmt"
+	"mysql_to_md/common"
+	"regexp"
+	"strings"
+)
+
+const (
+	// CHSqlTableIndex 查看数据表跳数索引SQL-clickhouse
+	CHSqlTableIndex = "SELECT `table`,name,type,expr,granularity from system.data_skipping_indices where database = '%s'%s order by `table`,name"
+	// CHSqlDictionary 查看字典数据源、布局、生命周期及属性SQL-clickhouse
+	CHSqlDictionary = "SELECT source,type,lifetime_min,lifetime_max,`key.names`,`key.types`,`attribute.names`,`attribute.types` from system.dictionaries where database = '%s' and name = '%s'"
+)
+
+// projectionReg 建表语句中投影的开始，投影查询由括号包围
+var projectionReg = regexp.MustCompile("(?m)^\\s*PROJECTION\\s+`?([^`\\s(]+)`?\\s*\\(")
+
+// extendIndex 补充表的跳数索引及投影
+func (c *Clickhouse) extendIndex(table *common.Table) error {
+	if c.indexes != nil {
+		table.Indexes = c.indexes[table.Name]
+	} else {
+		// older servers without system.data_skipping_indices have no skipping indexes to show
+		indexes, err := c.queryIndex(" and `table` = " + common.QuoteList([]string{table.Name}))
+		if err != nil {
+			c.Conf.Logf("execute query table index error,had ignored, detail is [%v]\n", err.Error())
+		}
+		table.Indexes = indexes[table.Name]
+	}
+	table.Projections = parseProjections(table.CreateSql)
+	return nil
+}
+
+// queryIndex 查询跳数索引，filter为附加的过滤条件
+func (c *Clickhouse) queryIndex(filter string) (map[string][]common.TableIndex, error) {
+	rows, err := c.DB.Raw(fmt.Sprintf(CHSqlTableIndex, c.Conf.Database, filter)).Rows()
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	indexes := make(map[string][]common.TableIndex)
+	for rows.Next() {
+		var tableName string
+		var index common.TableIndex
+		if err = rows.Scan(&tableName, &index.Name, &index.Type, &index.Expression, &index.Granularity); err != nil {
+			return nil, err
+		}
+		indexes[tableName] = append(indexes[tableName], index)
+	}
+	return indexes, rows.Err()
+}
+
+// extendDictionary 补充字典的数据源、布局、生命周期及属性
+func (c *Clickhouse) extendDictionary(table *common.Table) error {
+	rows, err := c.DB.Raw(fmt.Sprintf(CHSqlDictionary, c.Conf.Database, table.Name)).Rows()
+	if err != nil {
+		c.Conf.Logf("execute query dictionary error, detail is [%v]\n", err.Error())
+		return err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var dictionary common.Dictionary
+		err = rows.Scan(
+			&dictionary.Source,
+			&dictionary.Layout,
+			&dictionary.LifetimeMin,
+			&dictionary.LifetimeMax,
+			&dictionary.Keys,
+			&dictionary.KeyTypes,
+			&dictionary.AttributeNames,
+			&dictionary.AttributeTypes)
+		if err != nil {
+			return err
+		}
+		table.Dictionary = &dictionary
+	}
+	return rows.Err()
+}
+
+// parseProjections 从建表语句中解析投影，system.projections 只在较新的版本中存在
+func parseProjections(createSql string) []common.Projection {
+	var projections []common.Projection
+	for _, loc := range projectionReg.FindAllStringSubmatchIndex(createSql, -1) {
+		// the query ends at the matching right parenthesis
+		depth := 1
+		for i := loc[1]; i < len(createSql); i++ {
+			switch createSql[i] {
+			case '(':
+				depth++
+			case ')':
+				depth--
+			}
+			if depth == 0 {
+				projections = append(projections, common.Projection{
+					Name:  createSql[loc[2]:loc[3]],
+					Query: strings.Join(strings.Fields(createSql[loc[1]:i]), " "),
+				})
+				break
+			}
+		}
+	}
+	return projections
+}
@@ -0,0 +1,96 @@
+package ch
+
+import (
+	"fmt"
+	"mysql_to_md/common"
+	"regexp"
+	"strings"
+)
+
+const (
+	// CHSqlTableLineage 查看表的依赖、视图查询及引擎参数SQL-clickhouse
+	CHSqlTableLineage = "SELECT name,engine_full,dependencies_database,dependencies_table,as_select,create_table_query from system.tables where database = '%s' and is_temporary = 0 and not startsWith(name, '.inner') order by name"
+)
+
+var (
+	// mvTargetReg 物化视图 TO 的目标表
+	mvTargetReg = regexp.MustCompile("(?i)^CREATE MATERIALIZED VIEW\\s+\\S+\\s+TO\\s+([\\w.`]+)")
+	// selectTableReg 视图查询中 FROM、JOIN 的表
+	selectTableReg = regexp.MustCompile("(?i)\\b(?:FROM|JOIN)\\s+([\\w.`]+)")
+)
+
+// queryLineage 根据依赖关系、视图查询及引擎参数生成表之间的数据流向
+func (c *Clickhouse) queryLineage(schema *common.Schema) error {
+	rows, err := c.DB.Raw(fmt.Sprintf(CHSqlTableLineage, c.Conf.Database)).Rows()
+	if err != nil {
+		c.Conf.Logf("execute query table lineage error, detail is [%v]\n", err.Error())
+		return err
+	}
+	defer rows.Close()
+
+	type tableLineage struct {
+		name, engineFull, asSelect, createSql string
+		depDatabases, depTables               []string
+	}
+	var tables []tableLineage
+	known := make(map[string]bool)
+	for rows.Next() {
+		var t tableLineage
+		if err = rows.Scan(&t.name, &t.engineFull, &t.depDatabases, &t.depTables, &t.asSelect, &t.createSql); err != nil {
+			return err
+		}
+		tables = append(tables, t)
+		known[t.name] = true
+	}
+	if err = rows.Err(); err != nil {
+		return err
+	}
+
+	seen := make(map[common.LineageEdge]bool)
+	addEdge := func(from, to string) {
+		edge := common.LineageEdge{From: from, To: to}
+		if from == "" || to == "" || from == to || seen[edge] {
+			return
+		}
+		seen[edge] = true
+		schema.Lineage = append(schema.Lineage, edge)
+	}
+	for _, t := range tables {
+		// materialized views reading from this table
+		for i := range t.depTables {
+			addEdge(t.name, c.tableName(t.depDatabases[i], t.depTables[i]))
+		}
+		// source tables of views and materialized views
+		for _, match := range selectTableReg.FindAllStringSubmatch(t.asSelect, -1) {
+			if source := c.qualifiedName(match[1]); known[source] || strings.Contains(source, ".") {
+				addEdge(source, t.name)
+			}
+		}
+		// materialized view writes into the TO table
+		if match := mvTargetReg.FindStringSubmatch(t.createSql); match != nil {
+			addEdge(t.name, c.qualifiedName(match[1]))
+		}
+		// distributed table reads and writes the local table on each shard
+		if distributed := c.parseDistributed(t.engineFull); distributed != nil {
+			addEdge(distributed.LocalTable, t.name)
+		}
+	}
+	return nil
+}
+
+// tableName 当前库的表只保留表名，其他库的表为 database.table
+func (c *Clickhouse) tableName(database, table string) string {
+	if database == "" || database == c.Conf.Database {
+		return table
+	}
+	return database + "." + table
+}
+
+// qualifiedName 解析 `db`.`table` 形式的表名
+func (c *Clickhouse) qualifiedName(name string) string {
+	name = strings.ReplaceAll(name, "`", "")
+	if i := strings.Index(name, "."); i >= 0 {
+		return c.tableName(name[:i], name[i+1:])
+	}
+	return name
+}
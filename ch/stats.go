@@ -0,0 +1,61 @@
+package ch
+
+import (
+	"fmt"
+	"mysql_to_md/common"
+	"regexp"
+	"strings"
+)
+
+const (
+	// CHSqlTableStats 查看数据表行数、磁盘大小及活跃分区片段数SQL-clickhouse
+	CHSqlTableStats = "SELECT `table`,sum(rows),sum(bytes_on_disk),count() from system.parts where database = '%s' and active%s group by `table`"
+)
+
+// extendStats 补充表的数据量统计
+func (c *Clickhouse) extendStats(table *common.Table) error {
+	stat, ok := c.stats[table.Name]
+	if !ok {
+		stats, err := c.queryStats(" and `table` = " + common.QuoteList([]string{table.Name}))
+		if err != nil {
+			c.Conf.Logf("execute query table stats error, detail is [%v]\n", err.Error())
+			return err
+		}
+		stat = stats[table.Name]
+	}
+	table.Stats = stat
+	return nil
+}
+
+// queryStats 查询表的数据量统计，filter为附加的过滤条件
+func (c *Clickhouse) queryStats(filter string) (map[string]*common.TableStats, error) {
+	rows, err := c.DB.Raw(fmt.Sprintf(CHSqlTableStats, c.Conf.Database, filter)).Rows()
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	stats := make(map[string]*common.TableStats)
+	for rows.Next() {
+		var tableName string
+		var stat common.TableStats
+		if err = rows.Scan(&tableName, &stat.Rows, &stat.Bytes, &stat.Parts); err != nil {
+			return nil, err
+		}
+		stats[tableName] = &stat
+	}
+	return stats, rows.Err()
+}
+
+// parseColumnTTL 从建表语句中解析字段级TTL，SHOW CREATE TABLE 每个字段单独一行
+func parseColumnTTL(createSql string, columns []common.TableColumn) {
+	lines := strings.Split(createSql, "\n")
+	for i := range columns {
+		reg := regexp.MustCompile("^\\s*`?" + regexp.QuoteMeta(columns[i].ColumnName) + "`?\\s.*?\\sTTL\\s+(.+?)(?:\\s+COMMENT\\s+'.*')?,?$")
+		for _, line := range lines {
+			if match := reg.FindStringSubmatch(line); match != nil {
+				columns[i].TTL = match[1]
+				break
+			}
+		}
+	}
+}
@@ -0,0 +1,62 @@
+package ch
+
+import (
+	"fmt"
+	"mysql_to_md/common"
+	"regexp"
+)
+
+const (
+	// CHSqlTableStorage 查看数据表引擎及排序、分区、主键、采样键SQL-clickhouse
+	CHSqlTableStorage = "SELECT name,engine_full,sorting_key,partition_key,primary_key,sampling_key from system.tables where database = '%s'%s"
+)
+
+// ttlReg engine_full 中的表级TTL
+var ttlReg = regexp.MustCompile(`\bTTL\s+(.+?)(?:\s+SETTINGS\s|$)`)
+
+// extendStorage 补充表的存储结构
+func (c *Clickhouse) extendStorage(table *common.Table) error {
+	storage, ok := c.storages[table.Name]
+	if !ok {
+		storages, err := c.queryStorage(" and name = " + common.QuoteList([]string{table.Name}))
+		if err != nil {
+			c.Conf.Logf("execute query table storage error, detail is [%v]\n", err.Error())
+			return err
+		}
+		storage = storages[table.Name]
+	}
+	table.Storage = storage
+	return nil
+}
+
+// queryStorage 查询表的存储结构，filter为附加的过滤条件，视图等没有存储结构的对象不返回
+func (c *Clickhouse) queryStorage(filter string) (map[string]*common.StorageLayout, error) {
+	rows, err := c.DB.Raw(fmt.Sprintf(CHSqlTableStorage, c.Conf.Database, filter)).Rows()
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	storages := make(map[string]*common.StorageLayout)
+	for rows.Next() {
+		var tableName string
+		var storage common.StorageLayout
+		err = rows.Scan(
+			&tableName,
+			&storage.EngineFull,
+			&storage.SortingKey,
+			&storage.PartitionKey,
+			&storage.PrimaryKey,
+			&storage.SamplingKey)
+		if err != nil {
+			return nil, err
+		}
+		if storage.EngineFull == "" {
+			continue
+		}
+		if match := ttlReg.FindStringSubmatch(storage.EngineFull); match != nil {
+			storage.TTL = match[1]
+		}
+		storages[tableName] = &storage
+	}
+	return storages, rows.Err()
+}
@@ -0,0 +1,92 @@
+package common
+
+import (
+	"os"
+
+	"gopkg.in/yaml.v3"
+)
+
+// CommentSource 注释的来源
+type CommentSource string
+
+const (
+	// SourceDB 注释来自数据库
+	SourceDB CommentSource = "db"
+	// SourceDoc 注释来自注释补充文件，补充或覆盖了数据库中的注释
+	SourceDoc CommentSource = "doc"
+)
+
+// Annotations 注释补充文件，用于补充无法修改数据库的注释及说明，key为表名
+type Annotations struct {
+	Tables map[string]*TableAnnotation `yaml:"tables"`
+}
+
+// TableAnnotation 表的补充信息
+type TableAnnotation struct {
+	Comment     string   `yaml:"comment"`     // supply or override table comment
+	Description string   `yaml:"description"` // business description
+	Owners      []string `yaml:"owners"`
+	Tags        []string `yaml:"tags"`
+	Deprecated  string   `yaml:"deprecated"` // deprecation note, empty when not deprecated
+	// Columns 字段的补充信息，key为字段名
+	Columns map[string]*ColumnAnnotation `yaml:"columns"`
+}
+
+// ColumnAnnotation 字段的补充信息
+type ColumnAnnotation struct {
+	Comment     string   `yaml:"comment"`     // supply or override column comment
+	Description string   `yaml:"description"` // business description
+	Tags        []string `yaml:"tags"`
+	Deprecated  string   `yaml:"deprecated"` // deprecation note, empty when not deprecated
+	Example     string   `yaml:"example"`    // example value
+}
+
+// LoadAnnotations 读取yaml格式的注释补充文件
+func LoadAnnotations(path string) (*Annotations, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var annotations Annotations
+	if err = yaml.Unmarshal(content, &annotations); err != nil {
+		return nil, err
+	}
+	return &annotations, nil
+}
+
+// Annotate 将补充信息合入表，补充文件中的注释覆盖数据库中的注释
+func (a *TableAnnotation) Annotate(info *TableInfo) {
+	info.Annotation = a
+	if a.Comment != "" {
+		info.Comment.String, info.Comment.Valid = a.Comment, true
+		info.CommentSource = SourceDoc
+	}
+}
+
+// AnnotateColumns 将补充信息合入字段，返回补充文件中有但表中没有的字段，
+// 只写了字段名没有内容的补充信息（yaml中为null）视为没有补充
+func (a *TableAnnotation) AnnotateColumns(columns []TableColumn) []string {
+	found := make(map[string]bool)
+	for i := range columns {
+		annotation, ok := a.Columns[columns[i].ColumnName]
+		if !ok {
+			continue
+		}
+		found[columns[i].ColumnName] = true
+		if annotation == nil {
+			continue
+		}
+		columns[i].Annotation = annotation
+		if annotation.Comment != "" {
+			columns[i].ColumnComment.String, columns[i].ColumnComment.Valid = annotation.Comment, true
+			columns[i].CommentSource = SourceDoc
+		}
+	}
+	var missing []string
+	for name := range a.Columns {
+		if !found[name] {
+			missing = append(missing, name)
+		}
+	}
+	return missing
+}
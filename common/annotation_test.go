@@ -0,0 +1,54 @@
+package common
+
+import (
+	"database/sql"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestAnnotateColumns(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "annotations.yaml")
+	content := `tables:
+  user:
+    comment: 用户
+    columns:
+      id:
+      name:
+        comment: 用户名
+        example: alice
+      removed:
+        comment: 已删除的字段
+  empty:
+`
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+	annotations, err := LoadAnnotations(path)
+	if err != nil {
+		t.Fatalf("LoadAnnotations error: %v", err)
+	}
+	if annotation, ok := annotations.Tables["empty"]; !ok || annotation != nil {
+		t.Fatalf("annotation of table empty = %v, %v, want nil", annotation, ok)
+	}
+	annotation := annotations.Tables["user"]
+	if annotation.Columns["id"] != nil {
+		t.Fatalf("annotation of column id = %v, want nil", annotation.Columns["id"])
+	}
+
+	columns := []TableColumn{
+		{ColumnName: "id", ColumnComment: sql.NullString{String: "主键", Valid: true}},
+		{ColumnName: "name"},
+	}
+	missing := annotation.AnnotateColumns(columns)
+	if !reflect.DeepEqual(missing, []string{"removed"}) {
+		t.Errorf("missing = %v, want [removed]", missing)
+	}
+	if columns[0].Annotation != nil || columns[0].ColumnComment.String != "主键" || columns[0].CommentSource != "" {
+		t.Errorf("column id without annotation is changed: %+v", columns[0])
+	}
+	if columns[1].Annotation == nil || columns[1].ColumnComment.String != "用户名" || columns[1].CommentSource != SourceDoc {
+		t.Errorf("column name is not annotated: %+v", columns[1])
+	}
+}
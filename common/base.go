@@ -2,10 +2,10 @@ package common
 
 import (
 	"database/sql"
-	"flag"
 	"fmt"
-	"os"
+	"io"
 	"regexp"
+	"strings"
 )
 
 // TableColumn 表结构
@@ -17,15 +17,288 @@ type TableColumn struct {
 	IsNullable      string         `db:"IS_NULLABLE"`      // nullable
 	ColumnComment   sql.NullString `db:"COLUMN_COMMENT"`   // comment
 	ColumnDefault   sql.NullString `db:"COLUMN_DEFAULT"`   // default value
+	// mysql column attributes
+	Extra                sql.NullString `db:"EXTRA"`                 // auto_increment, on update, generated, invisible
+	CharacterSet         sql.NullString `db:"CHARACTER_SET_NAME"`    // charset
+	Collation            sql.NullString `db:"COLLATION_NAME"`        // collation
+	GenerationExpression sql.NullString `db:"GENERATION_EXPRESSION"` // generated column expression
+	SrsID                sql.NullInt64  `db:"SRS_ID"`                // spatial reference system id
+	InSortingKey         bool           `db:"is_in_sorting_key"`     // in sorting key, clickhouse
+	InPrimaryKey         bool           `db:"is_in_primary_key"`     // in primary key, clickhouse
+	// clickhouse column storage
+	Codec             string `db:"compression_codec"` // compression codec
+	TTL               string // column TTL, parsed from create sql
+	CompressedBytes   uint64 `db:"data_compressed_bytes"`   // compressed size
+	UncompressedBytes uint64 `db:"data_uncompressed_bytes"` // uncompressed size
+	// Values ENUM/SET 的可选值或注释中的取值说明
+	Values []ColumnValue
+	// CommentSource 注释来源，没有注释时为空，Annotation 注释补充文件中的信息
+	CommentSource CommentSource
+	Annotation    *ColumnAnnotation
 }
 
 // TableInfo 表信息
 type TableInfo struct {
 	Name    string         `db:"table_name"`    // name
 	Comment sql.NullString `db:"table_comment"` // comment
+	Engine  string         `db:"engine"`        // engine
+	Kind    TableKind      // kind, empty as KindTable
+	// mysql table properties
+	Collation     string         `db:"table_collation"` // default collation
+	RowFormat     string         `db:"row_format"`      // row format
+	Rows          sql.NullInt64  `db:"table_rows"`      // approximate rows
+	DataLength    sql.NullInt64  `db:"data_length"`     // data size
+	IndexLength   sql.NullInt64  `db:"index_length"`    // index size
+	AutoIncrement sql.NullInt64  `db:"auto_increment"`  // next auto increment value
+	CreateTime    sql.NullString `db:"create_time"`     // create time
+	UpdateTime    sql.NullString `db:"update_time"`     // update time
+	// CommentSource 注释来源，没有注释时为空，Annotation 注释补充文件中的信息
+	CommentSource CommentSource
+	Annotation    *TableAnnotation
+	// Shards 合并后的分表，表示结构相同的一组分表，Name 为其中第一个表，未合并为nil
+	Shards *ShardFamily
 }
 
-var dbConf Conf
+// ShardFamily 按数字或日期后缀分出的结构相同的一组表，如 order_0 ... order_127
+type ShardFamily struct {
+	Pattern string   // such as order_{0..127}, or order_{0..3,5} when suffixes are not contiguous
+	Members []string // tables with the same structure, ordered by suffix
+	// Differs 名称属于这组分表但结构不同的表，这些表仍单独输出
+	Differs []ShardDiff
+}
+
+// ShardDiff 结构与分表不同的表及差异说明
+type ShardDiff struct {
+	Table   string
+	Reasons []string
+}
+
+// DisplayName 文档中显示的表名，合并的分表为分表的名称模式
+func (t *TableInfo) DisplayName() string {
+	if t.Shards != nil {
+		return t.Shards.Pattern
+	}
+	return t.Name
+}
+
+// Charset 默认字符集，由排序规则的前缀得出，如 utf8mb4_general_ci 为 utf8mb4
+func (t *TableInfo) Charset() string {
+	if i := strings.Index(t.Collation, "_"); i > 0 {
+		return t.Collation[:i]
+	}
+	return t.Collation
+}
+
+// TableKind 数据库对象类型，文档按类型分组输出
+type TableKind string
+
+const (
+	KindTable            TableKind = "TABLE"
+	KindView             TableKind = "VIEW"
+	KindMaterializedView TableKind = "MATERIALIZED VIEW"
+	KindDistributed      TableKind = "DISTRIBUTED"
+	KindMerge            TableKind = "MERGE"
+	KindKafka            TableKind = "KAFKA"
+	KindDictionary       TableKind = "DICTIONARY"
+)
+
+// TableKinds 对象类型在文档中的先后顺序
+var TableKinds = []TableKind{KindTable, KindView, KindMaterializedView, KindDistributed, KindMerge, KindKafka, KindDictionary}
+
+// Title 对象类型的分组标题
+func (k TableKind) Title() string {
+	switch k {
+	case KindView:
+		return "视图"
+	case KindMaterializedView:
+		return "物化视图"
+	case KindDistributed:
+		return "分布式表"
+	case KindMerge:
+		return "Merge表"
+	case KindKafka:
+		return "Kafka表"
+	case KindDictionary:
+		return "字典"
+	}
+	return "表"
+}
+
+// TableGroup 同一类型或同一模块的表
+type TableGroup struct {
+	// Kind 按类型分组时为对象类型，按模块分组时为空
+	Kind        TableKind
+	Title       string
+	Description string
+	Tables      []TableInfo
+}
+
+// StorageLayout 表的存储结构，clickhouse
+type StorageLayout struct {
+	EngineFull   string `db:"engine_full"`   // engine with parameters
+	SortingKey   string `db:"sorting_key"`   // ORDER BY
+	PartitionKey string `db:"partition_key"` // PARTITION BY
+	PrimaryKey   string `db:"primary_key"`   // PRIMARY KEY
+	SamplingKey  string `db:"sampling_key"`  // SAMPLE BY
+	TTL          string // TTL, parsed from engine_full
+}
+
+// TableStats 表的数据量统计
+type TableStats struct {
+	Rows  uint64 // rows
+	Bytes uint64 // bytes on disk
+	Parts uint64 // active parts, clickhouse
+}
+
+// Table 单个表的文档内容
+type Table struct {
+	TableInfo
+	Columns   []TableColumn
+	CreateSql string
+	// Storage 存储结构，不支持的数据库为nil
+	Storage *StorageLayout
+	// Stats 数据量统计，未开启统计或不支持的数据库为nil
+	Stats *TableStats
+	// Indexes 跳数索引等二级索引
+	Indexes []TableIndex
+	// Projections 投影，clickhouse
+	Projections []Projection
+	// Dictionary 字典的数据源、布局、生命周期及属性，非字典为nil
+	Dictionary *Dictionary
+	// Partitioning 分区方式及各分区，未分区为nil
+	Partitioning *Partitioning
+	// Constraints 主键、唯一、外键及CHECK约束，mysql
+	Constraints []Constraint
+	// Triggers 表上的触发器
+	Triggers []Trigger
+	// Distributed 分布式表对应的集群及本地表
+	Distributed *DistributedTable
+	// FedBy 数据来源表，FeedsInto 数据写入的表
+	FedBy     []string
+	FeedsInto []string
+}
+
+// TableIndex 表的索引
+type TableIndex struct {
+	Name        string `db:"name"`        // name
+	Type        string `db:"type"`        // type, such as minmax, bloom_filter
+	Expression  string `db:"expr"`        // indexed expression
+	Granularity uint64 `db:"granularity"` // granularity, clickhouse skip index
+}
+
+// Constraint 表的约束，mysql
+type Constraint struct {
+	Name       string   `db:"CONSTRAINT_NAME"` // name
+	Type       string   `db:"CONSTRAINT_TYPE"` // PRIMARY KEY, UNIQUE, FOREIGN KEY, CHECK
+	Columns    []string // constrained columns
+	Expression string   `db:"CHECK_CLAUSE"` // check expression
+	Enforced   string   `db:"ENFORCED"`     // YES, NO, empty when unsupported
+	// ReferencedTable 外键引用的表，不在当前库时为 库名.表名
+	ReferencedTable   string
+	ReferencedColumns []string
+}
+
+// Partitioning 表的分区方式，mysql
+type Partitioning struct {
+	Method        string `db:"PARTITION_METHOD"`        // RANGE, LIST, HASH, KEY...
+	Expression    string `db:"PARTITION_EXPRESSION"`    // expression
+	SubMethod     string `db:"SUBPARTITION_METHOD"`     // subpartition method
+	SubExpression string `db:"SUBPARTITION_EXPRESSION"` // subpartition expression
+	Partitions    []Partition
+}
+
+// Partition 单个分区
+type Partition struct {
+	Name        string `db:"PARTITION_NAME"`        // name
+	SubName     string `db:"SUBPARTITION_NAME"`     // subpartition name
+	Description string `db:"PARTITION_DESCRIPTION"` // VALUES LESS THAN / VALUES IN
+	Rows        uint64 `db:"TABLE_ROWS"`            // estimated rows
+	Comment     string `db:"PARTITION_COMMENT"`     // comment
+}
+
+// Projection 投影，clickhouse
+type Projection struct {
+	Name  string
+	Query string
+}
+
+// Dictionary 字典，clickhouse
+type Dictionary struct {
+	Source      string   `db:"source"`       // source
+	Layout      string   `db:"type"`         // layout
+	LifetimeMin uint64   `db:"lifetime_min"` // lifetime min seconds
+	LifetimeMax uint64   `db:"lifetime_max"` // lifetime max seconds
+	Keys        []string `db:"key.names"`    // key names
+	KeyTypes    []string `db:"key.types"`    // key types
+	// Attributes 属性名及类型，顺序一致
+	AttributeNames []string `db:"attribute.names"`
+	AttributeTypes []string `db:"attribute.types"`
+}
+
+// DistributedTable 分布式表对应的集群及各分片上的本地表
+type DistributedTable struct {
+	Cluster     string
+	LocalTable  string // local table, database.table when not in current database
+	ShardingKey string
+	// Drift 各副本本地表结构不一致的说明，未校验或一致时为空
+	Drift []string
+}
+
+// Cluster 集群拓扑
+type Cluster struct {
+	Name     string
+	Shards   int
+	Replicas int // max replicas of shards
+	Hosts    []string
+}
+
+// LineageEdge 数据流向，From 的数据写入 To
+type LineageEdge struct {
+	From string
+	To   string
+}
+
+// Upstream 写入name的表
+func Upstream(edges []LineageEdge, name string) []string {
+	var names []string
+	for _, edge := range edges {
+		if edge.To == name {
+			names = append(names, edge.From)
+		}
+	}
+	return names
+}
+
+// Downstream name写入的表
+func Downstream(edges []LineageEdge, name string) []string {
+	var names []string
+	for _, edge := range edges {
+		if edge.From == name {
+			names = append(names, edge.To)
+		}
+	}
+	return names
+}
+
+// Schema 整个数据库的文档内容
+type Schema struct {
+	Database string
+	Tables   []TableInfo
+	// Groups 按类型分组后的表，Tables的顺序与分组顺序一致
+	Groups []TableGroup
+	// Lineage 表之间的数据流向，不支持的数据库为空
+	Lineage []LineageEdge
+	// Clusters 集群拓扑，未开启集群信息时为空
+	Clusters []Cluster
+	// Routines 存储过程及函数，Triggers 触发器，Events 定时事件
+	Routines []Routine
+	Triggers []Trigger
+	Events   []Event
+	// Notes 从已有文档中读取的手写备注，key为表名，重新生成时放回对应的表下
+	Notes map[string]string
+	// Capabilities 数据库类型支持的特性，渲染器据此省略不支持的内容
+	Capabilities Capabilities
+}
 
 // Conf 数据库配置
 type Conf struct {
@@ -38,47 +311,43 @@ type Conf struct {
 	Charset    string
 	Output     string
 	Tables     string
+	// DSN 完整的驱动连接串，指定后忽略host、port等连接参数
+	DSN string
+	// TLS 证书及校验配置
+	TLSCA         string
+	TLSCert       string
+	TLSKey        string
+	TLSSkipVerify bool
+	TLSServerName string
+	// Format 文档格式，对应已注册的Renderer
+	Format string
+	// Progress 进度日志输出，为nil时不输出
+	Progress io.Writer
+	// Concurrency 并发查询表元数据的协程数，小于1时按1处理
+	Concurrency int
+	// Stats 输出行数、大小、压缩比等数据量统计，每次生成都会变化
+	Stats bool
+	// Cluster 读取集群拓扑，VerifyReplicas 校验分布式表的本地表在各副本上结构一致
+	Cluster        bool
+	VerifyReplicas bool
+	// ValuePattern 从字段注释中解析取值说明的正则，第一个分组为值，第二个分组为含义，为空时使用 DefaultValuePattern
+	ValuePattern string
+	// CollapseShards 合并按数字或日期后缀分出的结构相同的表，只输出一次
+	CollapseShards bool
+	// GroupRules 按规则将表分成模块，GroupByPrefix 按表名第一个 '_' 及之前的前缀分成模块，规则优先
+	GroupRules    []GroupRule
+	GroupByPrefix bool
+	// Annotations 注释补充文件的内容，补充或覆盖数据库中的注释
+	Annotations *Annotations
+	// Split 分文件输出，Output为目录，每个表写入 tables/<表名>.md，目录页写入 index.md
+	Split bool
 }
 
-func init() {
-	// init flag for command
-	flag.CommandLine.Usage = func() {
-		fmt.Println("Usage: mysql_to_md [options...]\n" +
-			"--help  This help text" + "\n" +
-			"-s      dial select.     default mysql" + "\n" +
-			"-h      host.     default 127.0.0.1" + "\n" +
-			"-u      username. default root" + "\n" +
-			"-p      password. default root" + "\n" +
-			"-d      database. default mysql" + "\n" +
-			"-P      port.     default 3306" + "\n" +
-			"-c      charset.  default utf8" + "\n" +
-			"-o      output.   default current location\n" +
-			"-t      tables.   default all table and support ',' separator for filter, every item can use regexp" +
-			"")
-		os.Exit(0)
-	}
-	dialselect := flag.String("s", "mysql", "dialselect(mysql)")
-	host := flag.String("h", "127.0.0.1", "host(127.0.0.1)")
-	username := flag.String("u", "root", "username(root)")
-	password := flag.String("p", "root", "password(root)")
-	database := flag.String("d", "mysql", "database(mysql)")
-	port := flag.Int("P", 3306, "port(3306)")
-	charset := flag.String("c", "utf8", "charset(utf8)")
-	output := flag.String("o", "", "output location")
-	tables := flag.String("t", "", "choose tables")
-	flag.Parse()
-	dbConf = Conf{
-		Dialselect: *dialselect,
-		Host:       *host,
-		Username:   *username,
-		Password:   *password,
-		Database:   *database,
-		Port:       *port,
-		Charset:    *charset,
-		Output:     *output,
-		Tables:     *tables,
+// Logf 输出进度日志，Progress 为nil时不输出
+func (c *Conf) Logf(format string, a ...interface{}) {
+	if c != nil && c.Progress != nil {
+		fmt.Fprintf(c.Progress, format, a...)
 	}
-	fmt.Println(dbConf)
 }
 
 // GetTargetIndexMap
@@ -96,7 +365,24 @@ func GetTargetIndexMap(tableNameArr []string, item string) map[int]int {
 	return indexMap
 }
 
-// GetDBConf 获取db配置
-func GetDBConf() *Conf {
-	return &dbConf
+// FilterTables 按 ',' 分隔的正则过滤表，保持表原有的顺序
+func FilterTables(tables []TableInfo, filter string) []TableInfo {
+	var tableArray []string
+	for _, table := range tables {
+		tableArray = append(tableArray, table.Name)
+	}
+	indexMap := make(map[int]int)
+	for _, item := range strings.Split(filter, ",") {
+		for k, v := range GetTargetIndexMap(tableArray, item) {
+			indexMap[k] = v
+		}
+	}
+
+	var tableCollect []TableInfo
+	for i, table := range tables {
+		if _, ok := indexMap[i]; ok {
+			tableCollect = append(tableCollect, table)
+		}
+	}
+	return tableCollect
 }
@@ -0,0 +1,35 @@
+package common
+
+import (
+	"os"
+
+	"gopkg.in/yaml.v3"
+)
+
+// GroupRule 表分组规则，表名以 Prefix 开头或匹配正则 Pattern 即属于该分组
+type GroupRule struct {
+	Name        string `yaml:"name"`
+	Description string `yaml:"description"`
+	Prefix      string `yaml:"prefix"`
+	Pattern     string `yaml:"pattern"`
+}
+
+// FileConfig 配置文件的内容
+type FileConfig struct {
+	// Groups 表分组规则，表按顺序匹配第一个规则
+	Groups []GroupRule `yaml:"groups"`
+}
+
+// LoadConfig 读取yaml配置文件并合入conf
+func LoadConfig(path string, conf *Conf) error {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	var fileConf FileConfig
+	if err = yaml.Unmarshal(content, &fileConf); err != nil {
+		return err
+	}
+	conf.GroupRules = append(conf.GroupRules, fileConf.Groups...)
+	return nil
+}
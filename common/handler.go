@@ -0,0 +1,101 @@
+package common
+
+import (
+	"context"
+	"fmt"
+	"hash/fnv"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// Handler md导出处理，持有连接的Handler应实现 io.Closer，生成文档后关闭
+type Handler interface {
+	QueryTables() ([]TableInfo, error)
+	QueryTableColumn(tableName string) ([]TableColumn, error)
+	QueryCreateSql(tableName string) (string, error)
+}
+
+// Capabilities 数据库类型支持的特性
+type Capabilities struct {
+	Comments    bool // 表及字段注释，仅用于 --list-dialects 展示
+	ForeignKeys bool // 外键约束，为false时文档中不输出外键约束及ER图中的关系
+}
+
+// Dialect 数据库类型，由各数据库包在init中注册
+type Dialect struct {
+	Name        string
+	Description string
+	// BuildDSN 根据配置生成连接串
+	BuildDSN func(conf *Conf) (string, error)
+	// NewHandler 使用连接串连接数据库并创建Handler
+	NewHandler   func(ctx context.Context, conf *Conf, dsn string) (Handler, error)
+	Capabilities Capabilities
+}
+
+var (
+	dialectLock sync.RWMutex
+	dialects    = make(map[string]Dialect)
+)
+
+// RegisterDialect 注册数据库类型，同名注册会覆盖已有的
+func RegisterDialect(dialect Dialect) {
+	if dialect.Name == "" || dialect.BuildDSN == nil || dialect.NewHandler == nil {
+		panic("common: RegisterDialect dialect name, BuildDSN and NewHandler are required")
+	}
+	dialectLock.Lock()
+	dialects[dialect.Name] = dialect
+	dialectLock.Unlock()
+}
+
+// GetDialect 获取已注册的数据库类型
+func GetDialect(name string) (Dialect, bool) {
+	dialectLock.RLock()
+	dialect, ok := dialects[name]
+	dialectLock.RUnlock()
+	return dialect, ok
+}
+
+// Dialects 已注册的数据库类型，按名称排序
+func Dialects() []Dialect {
+	dialectLock.RLock()
+	var list []Dialect
+	for _, dialect := range dialects {
+		list = append(list, dialect)
+	}
+	dialectLock.RUnlock()
+	sort.Slice(list, func(i, j int) bool { return list[i].Name < list[j].Name })
+	return list
+}
+
+// Prefetcher 支持批量查询元数据的Handler，生成文档前调用一次，
+// 之后的逐表查询优先读取批量结果，结果中没有的表再单独查询
+type Prefetcher interface {
+	Prefetch(tables []TableInfo) error
+}
+
+// TableExtender 支持额外表信息的Handler，在查询完字段及建表语句后调用，补充table中的其他信息
+type TableExtender interface {
+	ExtendTable(table *Table) error
+}
+
+// SchemaExtender 支持数据库级别信息的Handler，在输出文档前调用，补充schema中的其他信息
+type SchemaExtender interface {
+	ExtendSchema(schema *Schema) error
+}
+
+// QuoteList 将名称转换为 'a','b' 的形式，用于拼接IN条件
+func QuoteList(names []string) string {
+	quoted := make([]string, 0, len(names))
+	for _, name := range names {
+		quoted = append(quoted, "'"+strings.ReplaceAll(strings.ReplaceAll(name, `\`, `\\`), "'", `\'`)+"'")
+	}
+	return strings.Join(quoted, ",")
+}
+
+// NameHash 名称的短哈希，用于名称中的字符被替换后区分原本不同的名称
+func NameHash(name string) string {
+	h := fnv.New32a()
+	h.Write([]byte(name))
+	return fmt.Sprintf("%08x", h.Sum32())
+}
@@ -0,0 +1,65 @@
+package common
+
+import (
+	"io"
+	"sort"
+	"sync"
+)
+
+// Renderer 文档渲染，按 Begin、(Group、Table...)...、End 的顺序调用
+type Renderer interface {
+	Begin(w io.Writer, schema *Schema) error
+	Group(w io.Writer, group *TableGroup) error
+	Table(w io.Writer, index int, table *Table) error
+	End(w io.Writer, schema *Schema) error
+}
+
+// GroupEnder 可选接口，每个分组的表全部输出后调用
+type GroupEnder interface {
+	GroupEnd(w io.Writer, group *TableGroup) error
+}
+
+// NoteParser 可选接口，从已有文档中解析手写的备注并合入notes，key为表名
+type NoteParser interface {
+	ParseNotes(r io.Reader, notes map[string]string) error
+}
+
+// Extensioner 可选接口，指定自动命名时输出文件的扩展名，未实现时为 .md
+type Extensioner interface {
+	Extension() string
+}
+
+// RendererFunc 根据配置创建Renderer
+type RendererFunc func(conf *Conf) Renderer
+
+var (
+	rendererLock sync.RWMutex
+	renderers    = make(map[string]RendererFunc)
+)
+
+// RegisterRenderer 注册文档格式，同名注册会覆盖已有的
+func RegisterRenderer(name string, fn RendererFunc) {
+	rendererLock.Lock()
+	renderers[name] = fn
+	rendererLock.Unlock()
+}
+
+// GetRenderer 获取已注册的文档格式
+func GetRenderer(name string) (RendererFunc, bool) {
+	rendererLock.RLock()
+	fn, ok := renderers[name]
+	rendererLock.RUnlock()
+	return fn, ok
+}
+
+// RendererNames 已注册的文档格式名称
+func RendererNames() []string {
+	rendererLock.RLock()
+	var names []string
+	for name := range renderers {
+		names = append(names, name)
+	}
+	rendererLock.RUnlock()
+	sort.Strings(names)
+	return names
+}
@@ -0,0 +1,38 @@
+package common
+
+import "database/sql"
+
+// Routine 存储过程或函数
+type Routine struct {
+	Name       string             `db:"ROUTINE_NAME"`       // name
+	Type       string             `db:"ROUTINE_TYPE"`       // PROCEDURE or FUNCTION
+	Returns    sql.NullString     `db:"DTD_IDENTIFIER"`     // return type of function
+	Comment    string             `db:"ROUTINE_COMMENT"`    // comment
+	Body       sql.NullString     `db:"ROUTINE_DEFINITION"` // body, null without privilege
+	Parameters []RoutineParameter // parameters
+}
+
+// RoutineParameter 存储过程或函数的参数
+type RoutineParameter struct {
+	Mode string `db:"PARAMETER_MODE"` // IN, OUT, INOUT, empty for function
+	Name string `db:"PARAMETER_NAME"` // name
+	Type string `db:"DTD_IDENTIFIER"` // type
+}
+
+// Trigger 触发器
+type Trigger struct {
+	Name      string `db:"TRIGGER_NAME"`       // name
+	Event     string `db:"EVENT_MANIPULATION"` // INSERT, UPDATE, DELETE
+	Table     string `db:"EVENT_OBJECT_TABLE"` // table
+	Timing    string `db:"ACTION_TIMING"`      // BEFORE, AFTER
+	Statement string `db:"ACTION_STATEMENT"`   // body
+}
+
+// Event 定时事件
+type Event struct {
+	Name     string         `db:"EVENT_NAME"` // name
+	Schedule string         // schedule, such as EVERY 1 DAY STARTS ...
+	Status   string         `db:"STATUS"`           // ENABLED, DISABLED
+	Comment  string         `db:"EVENT_COMMENT"`    // comment
+	Body     sql.NullString `db:"EVENT_DEFINITION"` // body
+}
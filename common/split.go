@@ -0,0 +1,36 @@
+package common
+
+import "strings"
+
+// TablesDir 分文件输出时表文档所在的目录，相对于目录页
+const TablesDir = "tables"
+
+// fileNameReplacer 替换文件名中各系统不允许的字符
+var fileNameReplacer = strings.NewReplacer("/", "_", "\\", "_", ":", "_", "*", "_", "?", "_", "\"", "_", "<", "_", ">", "_", "|", "_")
+
+// TableFileName 分文件输出时表文档的文件名，表名中有字符被替换时追加表名的哈希，a/b 与 a_b 不会得到相同的文件名
+func TableFileName(name string) string {
+	fileName := fileNameReplacer.Replace(name)
+	if fileName != name {
+		fileName += "-" + NameHash(name)
+	}
+	return fileName + ".md"
+}
+
+// TableFileNames 各表的文档文件名，key为表名。文件系统可能不区分大小写，
+// 仅大小写不同的表名都追加表名的哈希，结果只取决于表名，不受表的顺序影响
+func TableFileNames(tables []TableInfo) map[string]string {
+	folded := make(map[string]int, len(tables))
+	for _, table := range tables {
+		folded[strings.ToLower(TableFileName(table.Name))]++
+	}
+	result := make(map[string]string, len(tables))
+	for _, table := range tables {
+		fileName := TableFileName(table.Name)
+		if folded[strings.ToLower(fileName)] > 1 {
+			fileName = strings.TrimSuffix(fileName, ".md") + "-" + NameHash(table.Name) + ".md"
+		}
+		result[table.Name] = fileName
+	}
+	return result
+}
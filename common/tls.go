@@ -0,0 +1,52 @@
+package common
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// TLSConfigName 注册到数据库驱动中的tls配置名称，驱动的tls配置是进程内全局的，
+// 名称由TLS参数生成，不同参数的多次生成互不覆盖
+func TLSConfigName(conf *Conf) string {
+	return "mysql_to_md_" + NameHash(strings.Join([]string{conf.TLSCA, conf.TLSCert, conf.TLSKey, conf.TLSServerName, strconv.FormatBool(conf.TLSSkipVerify)}, "\x00"))
+}
+
+// BuildTLSConfig 根据TLS参数生成tls配置，未指定任何TLS参数时返回nil
+func BuildTLSConfig(conf *Conf) (*tls.Config, error) {
+	if conf.TLSCA == "" && conf.TLSCert == "" && conf.TLSKey == "" && conf.TLSServerName == "" && !conf.TLSSkipVerify {
+		return nil, nil
+	}
+	tlsConfig := &tls.Config{
+		ServerName:         conf.TLSServerName,
+		InsecureSkipVerify: conf.TLSSkipVerify,
+	}
+	// custom ca certificate, such as self-signed or cloud vendor ca
+	if conf.TLSCA != "" {
+		pem, err := os.ReadFile(conf.TLSCA)
+		if err != nil {
+			return nil, err
+		}
+		rootCertPool := x509.NewCertPool()
+		if !rootCertPool.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("no certificate found in tls ca file %s", conf.TLSCA)
+		}
+		tlsConfig.RootCAs = rootCertPool
+	}
+	// client certificate for mutual tls
+	if conf.TLSCert != "" || conf.TLSKey != "" {
+		if conf.TLSCert == "" || conf.TLSKey == "" {
+			return nil, errors.New("tls client cert and key must be specified together")
+		}
+		cert, err := tls.LoadX509KeyPair(conf.TLSCert, conf.TLSKey)
+		if err != nil {
+			return nil, err
+		}
+		tlsConfig.Certificates = []tls.Certificate{cert}
+	}
+	return tlsConfig, nil
+}
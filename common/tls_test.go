@@ -0,0 +1,206 @@
+package common
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"errors"
+	"io"
+	"math/big"
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// writeCert 生成证书及私钥并写入pem文件，parent 为nil时生成自签名证书
+func writeCert(t *testing.T, dir, name string, template *x509.Certificate, parent *x509.Certificate, parentKey *ecdsa.PrivateKey) (*x509.Certificate, *ecdsa.PrivateKey) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if parent == nil {
+		parent, parentKey = template, key
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, parent, &key.PublicKey, parentKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	keyDer, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	certPem := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})
+	keyPem := pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDer})
+	if err = os.WriteFile(filepath.Join(dir, name+".pem"), certPem, 0600); err != nil {
+		t.Fatal(err)
+	}
+	if err = os.WriteFile(filepath.Join(dir, name+"-key.pem"), keyPem, 0600); err != nil {
+		t.Fatal(err)
+	}
+	cert, err := x509.ParseCertificate(der)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return cert, key
+}
+
+// selfSigned 在临时目录生成自签名ca、服务端证书及客户端证书
+func selfSigned(t *testing.T) string {
+	dir := t.TempDir()
+	now := time.Now()
+	ca, caKey := writeCert(t, dir, "ca", &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "test ca"},
+		NotBefore:             now.Add(-time.Hour),
+		NotAfter:              now.Add(time.Hour),
+		IsCA:                  true,
+		KeyUsage:              x509.KeyUsageCertSign,
+		BasicConstraintsValid: true,
+	}, nil, nil)
+	writeCert(t, dir, "server", &x509.Certificate{
+		SerialNumber: big.NewInt(2),
+		Subject:      pkix.Name{CommonName: "db.test"},
+		DNSNames:     []string{"db.test"},
+		NotBefore:    now.Add(-time.Hour),
+		NotAfter:     now.Add(time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+	}, ca, caKey)
+	writeCert(t, dir, "client", &x509.Certificate{
+		SerialNumber: big.NewInt(3),
+		Subject:      pkix.Name{CommonName: "client"},
+		NotBefore:    now.Add(-time.Hour),
+		NotAfter:     now.Add(time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+	}, ca, caKey)
+	return dir
+}
+
+// handshake 启动要求客户端证书的tls服务，用 config 连接并完成握手
+func handshake(t *testing.T, dir string, config *tls.Config) error {
+	cert, err := tls.LoadX509KeyPair(filepath.Join(dir, "server.pem"), filepath.Join(dir, "server-key.pem"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	caPem, err := os.ReadFile(filepath.Join(dir, "ca.pem"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	clientCAs := x509.NewCertPool()
+	clientCAs.AppendCertsFromPEM(caPem)
+	listener, err := tls.Listen("tcp", "127.0.0.1:0", &tls.Config{
+		Certificates: []tls.Certificate{cert},
+		ClientCAs:    clientCAs,
+		ClientAuth:   tls.RequireAndVerifyClientCert,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer listener.Close()
+	go func() {
+		conn, err := listener.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		_ = conn.(*tls.Conn).Handshake()
+	}()
+
+	conn, err := tls.DialWithDialer(&net.Dialer{Timeout: 5 * time.Second}, "tcp", listener.Addr().String(), config)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+	// tls 1.3 服务端校验客户端证书的结果在首次读取时返回
+	_ = conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+	_, err = conn.Read(make([]byte, 1))
+	if errors.Is(err, io.EOF) {
+		return nil
+	}
+	return err
+}
+
+func TestBuildTLSConfigNone(t *testing.T) {
+	config, err := BuildTLSConfig(&Conf{})
+	if err != nil || config != nil {
+		t.Errorf("BuildTLSConfig without tls params = %v, %v, want nil, nil", config, err)
+	}
+}
+
+func TestBuildTLSConfigSelfSigned(t *testing.T) {
+	dir := selfSigned(t)
+	config, err := BuildTLSConfig(&Conf{
+		TLSCA:         filepath.Join(dir, "ca.pem"),
+		TLSCert:       filepath.Join(dir, "client.pem"),
+		TLSKey:        filepath.Join(dir, "client-key.pem"),
+		TLSServerName: "db.test",
+	})
+	if err != nil {
+		t.Fatalf("BuildTLSConfig error: %v", err)
+	}
+	if err = handshake(t, dir, config); err != nil {
+		t.Errorf("handshake error: %v", err)
+	}
+}
+
+func TestBuildTLSConfigUnknownCA(t *testing.T) {
+	dir := selfSigned(t)
+	config, err := BuildTLSConfig(&Conf{
+		TLSCert:       filepath.Join(dir, "client.pem"),
+		TLSKey:        filepath.Join(dir, "client-key.pem"),
+		TLSServerName: "db.test",
+	})
+	if err != nil {
+		t.Fatalf("BuildTLSConfig error: %v", err)
+	}
+	if err = handshake(t, dir, config); err == nil {
+		t.Error("handshake with self-signed certificate should fail without ca")
+	}
+}
+
+func TestBuildTLSConfigMismatchedServerName(t *testing.T) {
+	dir := selfSigned(t)
+	config, err := BuildTLSConfig(&Conf{
+		TLSCA:         filepath.Join(dir, "ca.pem"),
+		TLSCert:       filepath.Join(dir, "client.pem"),
+		TLSKey:        filepath.Join(dir, "client-key.pem"),
+		TLSServerName: "other.test",
+	})
+	if err != nil {
+		t.Fatalf("BuildTLSConfig error: %v", err)
+	}
+	if err = handshake(t, dir, config); err == nil {
+		t.Error("handshake with mismatched server name should fail")
+	}
+}
+
+func TestBuildTLSConfigCertWithoutKey(t *testing.T) {
+	dir := selfSigned(t)
+	if _, err := BuildTLSConfig(&Conf{TLSCert: filepath.Join(dir, "client.pem")}); err == nil {
+		t.Error("BuildTLSConfig with cert but no key should fail")
+	}
+}
+
+func TestTLSConfigName(t *testing.T) {
+	conf := &Conf{TLSCA: "ca.pem", TLSServerName: "db.test"}
+	if TLSConfigName(conf) != TLSConfigName(&Conf{TLSCA: "ca.pem", TLSServerName: "db.test"}) {
+		t.Error("tls config name of the same params changed")
+	}
+	others := []*Conf{
+		{TLSCA: "other-ca.pem", TLSServerName: "db.test"},
+		{TLSCA: "ca.pem", TLSServerName: "db.test", TLSSkipVerify: true},
+		{TLSCA: "ca.pem", TLSServerName: "db.test", TLSCert: "client.pem", TLSKey: "client-key.pem"},
+	}
+	for _, other := range others {
+		if TLSConfigName(other) == TLSConfigName(conf) {
+			t.Errorf("tls config name of %+v is the same as %+v", other, conf)
+		}
+	}
+}
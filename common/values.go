@@ -0,0 +1,83 @@
+package common
+
+import (
+	"regexp"
+	"strings"
+)
+
+// DefaultValuePattern 默认的注释取值说明格式，匹配如 "状态：0-待支付 1-已支付 2-已取消"，第一个分组为值，第二个分组为含义，
+// 含义不能以数字开头，避免把 "2024-01-01" 这样的日期及 "1-10, 20-30" 这样的范围当作取值说明
+const DefaultValuePattern = `(-?\d+)\s*[-=:：]\s*([^\s\d,，;；、|][^\s,，;；、|]*)`
+
+// ColumnValue 字段的可选值及含义
+type ColumnValue struct {
+	Value string
+	Label string
+}
+
+// enumPattern ENUM、SET 及 clickhouse Enum8、Enum16 的取值定义
+var enumPattern = regexp.MustCompile(`(?i)\b(enum|enum8|enum16|set)\s*\(`)
+
+// ParseValues 从 ENUM/SET 类型定义及注释中解析字段的可选值，注释中至少匹配到两个取值才作为取值说明，
+// 类型定义与注释都有时以类型定义的值为准，含义取自注释
+func ParseValues(column *TableColumn, pattern *regexp.Regexp) []ColumnValue {
+	labels := make(map[string]string)
+	var commented []ColumnValue
+	if pattern != nil {
+		for _, match := range pattern.FindAllStringSubmatch(column.ColumnComment.String, -1) {
+			if len(match) < 3 {
+				break
+			}
+			if _, ok := labels[match[1]]; ok {
+				continue
+			}
+			labels[match[1]] = match[2]
+			commented = append(commented, ColumnValue{Value: match[1], Label: match[2]})
+		}
+		if len(commented) < 2 {
+			labels, commented = map[string]string{}, nil
+		}
+	}
+	loc := enumPattern.FindStringIndex(column.ColumnType)
+	if loc == nil {
+		return commented
+	}
+	var values []ColumnValue
+	for _, value := range quotedStrings(column.ColumnType[loc[1]:]) {
+		values = append(values, ColumnValue{Value: value, Label: labels[value]})
+	}
+	return values
+}
+
+// quotedStrings 按顺序取出单引号包裹的字符串，支持两个单引号及反斜杠转义，遇到引号外的 ')' 结束
+func quotedStrings(s string) []string {
+	var result []string
+	var current strings.Builder
+	quoted := false
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		if !quoted {
+			if c == '\'' {
+				quoted = true
+				current.Reset()
+			} else if c == ')' {
+				break
+			}
+			continue
+		}
+		switch {
+		case c == '\\' && i+1 < len(s):
+			i++
+			current.WriteByte(s[i])
+		case c == '\'' && i+1 < len(s) && s[i+1] == '\'':
+			i++
+			current.WriteByte('\'')
+		case c == '\'':
+			quoted = false
+			result = append(result, current.String())
+		default:
+			current.WriteByte(c)
+		}
+	}
+	return result
+}
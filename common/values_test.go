@@ -0,0 +1,84 @@
+package common
+
+import (
+	"database/sql"
+	"reflect"
+	"regexp"
+	"testing"
+)
+
+func TestParseValues(t *testing.T) {
+	pattern := regexp.MustCompile(DefaultValuePattern)
+	cases := []struct {
+		name       string
+		columnType string
+		comment    string
+		values     []ColumnValue
+	}{
+		{
+			name:       "comment",
+			columnType: "tinyint(4)",
+			comment:    "状态：0-待支付 1-已支付 2-已取消",
+			values:     []ColumnValue{{"0", "待支付"}, {"1", "已支付"}, {"2", "已取消"}},
+		},
+		{
+			name:       "separators",
+			columnType: "int",
+			comment:    "类型 1=普通，2:会员；-1：删除",
+			values:     []ColumnValue{{"1", "普通"}, {"2", "会员"}, {"-1", "删除"}},
+		},
+		{
+			name:       "single value is not a value list",
+			columnType: "int",
+			comment:    "1-启用",
+		},
+		{
+			name:       "date",
+			columnType: "varchar(10)",
+			comment:    "生效日期，如 2024-01-01 或 2024-12-31",
+		},
+		{
+			name:       "range",
+			columnType: "varchar(32)",
+			comment:    "分段，如 1-10, 20-30",
+		},
+		{
+			name:       "letter enum values get no labels from the default pattern",
+			columnType: "enum('a','b','c')",
+			comment:    "a-甲 b-乙",
+			values:     []ColumnValue{{"a", ""}, {"b", ""}, {"c", ""}},
+		},
+		{
+			name:       "clickhouse enum with comment labels",
+			columnType: "Enum8('1' = 1, '2' = 2)",
+			comment:    "1-男 2-女",
+			values:     []ColumnValue{{"1", "男"}, {"2", "女"}},
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			column := &TableColumn{ColumnType: c.columnType, ColumnComment: sql.NullString{String: c.comment, Valid: true}}
+			if values := ParseValues(column, pattern); !reflect.DeepEqual(values, c.values) {
+				t.Errorf("ParseValues = %v, want %v", values, c.values)
+			}
+		})
+	}
+}
+
+func TestQuotedStrings(t *testing.T) {
+	cases := []struct {
+		s      string
+		result []string
+	}{
+		{"'a','b','c')", []string{"a", "b", "c"}},
+		{`'it''s','a\'b','x,y')`, []string{"it's", "a'b", "x,y"}},
+		{"'a)b','c') DEFAULT 'd'", []string{"a)b", "c"}},
+		{"'a' = 1, 'b' = 2)", []string{"a", "b"}},
+		{")", nil},
+	}
+	for _, c := range cases {
+		if result := quotedStrings(c.s); !reflect.DeepEqual(result, c.result) {
+			t.Errorf("quotedStrings(%q) = %q, want %q", c.s, result, c.result)
+		}
+	}
+}
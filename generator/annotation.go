@@ -0,0 +1,57 @@
+package generator
+
+import (
+	"fmt"
+	"mysql_to_md/common"
+	"sort"
+)
+
+// annotateTables 将注释补充文件合入表信息并标记注释来源，补充文件中有但库中没有的表输出警告
+func annotateTables(tables []common.TableInfo, opts *Options) {
+	found := make(map[string]bool)
+	for i := range tables {
+		if opts.Annotations != nil {
+			if annotation, ok := opts.Annotations.Tables[tables[i].Name]; ok {
+				found[tables[i].Name] = true
+				if annotation != nil {
+					annotation.Annotate(&tables[i])
+				}
+			}
+		}
+		if tables[i].CommentSource == "" && tables[i].Comment.String != "" {
+			tables[i].CommentSource = common.SourceDB
+		}
+	}
+	// tables not selected are not missing
+	if opts.Annotations == nil || opts.Tables != "" || opts.Progress == nil {
+		return
+	}
+	var missing []string
+	for name := range opts.Annotations.Tables {
+		if !found[name] {
+			missing = append(missing, name)
+		}
+	}
+	sort.Strings(missing)
+	for _, name := range missing {
+		fmt.Fprintf(opts.Progress, "annotation of table %s is ignored, the table does not exist\n", name)
+	}
+}
+
+// annotateColumns 将注释补充文件合入表的字段并标记注释来源，补充文件中有但表中没有的字段输出警告
+func annotateColumns(table *common.Table, opts *Options) {
+	if annotation := table.Annotation; annotation != nil {
+		missing := annotation.AnnotateColumns(table.Columns)
+		sort.Strings(missing)
+		for _, name := range missing {
+			if opts.Progress != nil {
+				fmt.Fprintf(opts.Progress, "annotation of column %s.%s is ignored, the column does not exist\n", table.Name, name)
+			}
+		}
+	}
+	for i := range table.Columns {
+		if table.Columns[i].CommentSource == "" && table.Columns[i].ColumnComment.String != "" {
+			table.Columns[i].CommentSource = common.SourceDB
+		}
+	}
+}
@@ -0,0 +1,82 @@
+package generator
+
+import (
+	"context"
+	"mysql_to_md/common"
+	"sync"
+)
+
+// fetchResult 单个表的查询结果
+type fetchResult struct {
+	table *common.Table
+	err   error
+}
+
+// fetchTables 使用concurrency个协程并发查询表的字段及建表语句，
+// 并按tables原有顺序依次回调emit，保证输出顺序与串行查询一致
+func fetchTables(ctx context.Context, handler common.Handler, tables []common.TableInfo, concurrency int,
+	emit func(index int, table *common.Table) error) error {
+	if concurrency < 1 {
+		concurrency = 1
+	}
+	// cancel runs before wg.Wait, so that blocked workers exit on error
+	var wg sync.WaitGroup
+	defer wg.Wait()
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	results := make([]chan fetchResult, len(tables))
+	for i := range results {
+		results[i] = make(chan fetchResult, 1)
+	}
+	// window limits how far workers run ahead of emit, so finished tables don't pile up in memory
+	window := make(chan struct{}, concurrency*2)
+	jobs := make(chan int)
+	go func() {
+		defer close(jobs)
+		for i := range tables {
+			select {
+			case window <- struct{}{}:
+			case <-ctx.Done():
+				return
+			}
+			select {
+			case jobs <- i:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	for w := 0; w < concurrency; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range jobs {
+				if ctx.Err() != nil {
+					results[i] <- fetchResult{err: ctx.Err()}
+					continue
+				}
+				table, err := queryTable(handler, tables[i])
+				results[i] <- fetchResult{table: table, err: err}
+			}
+		}()
+	}
+
+	for i := range tables {
+		var result fetchResult
+		select {
+		case result = <-results[i]:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+		if result.err != nil {
+			return result.err
+		}
+		if err := emit(i, result.table); err != nil {
+			return err
+		}
+		<-window
+	}
+	return nil
+}
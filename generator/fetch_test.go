@@ -0,0 +1,125 @@
+package generator
+
+import (
+	"context"
+	"errors"
+	"math/rand"
+	"mysql_to_md/common"
+	"runtime"
+	"strconv"
+	"testing"
+	"time"
+)
+
+// delayedHandler n个表，每个表查询字段时随机等待不超过maxDelay
+func delayedHandler(n int, maxDelay time.Duration) *fakeHandler {
+	random := rand.New(rand.NewSource(1))
+	handler := &fakeHandler{delays: make(map[string]time.Duration), failing: make(map[string]bool)}
+	for i := 0; i < n; i++ {
+		name := "table_" + strconv.Itoa(i)
+		handler.tables = append(handler.tables, common.TableInfo{Name: name})
+		handler.delays[name] = time.Duration(random.Int63n(int64(maxDelay)))
+	}
+	return handler
+}
+
+// checkGoroutines 等待fetchTables启动的协程全部退出
+func checkGoroutines(t *testing.T, before int) {
+	deadline := time.Now().Add(time.Second)
+	for runtime.NumGoroutine() > before {
+		if time.Now().After(deadline) {
+			t.Fatalf("goroutines left after fetchTables: %d, want %d", runtime.NumGoroutine(), before)
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+func TestFetchTablesOrder(t *testing.T) {
+	handler := delayedHandler(100, 3*time.Millisecond)
+	for _, concurrency := range []int{0, 1, 4, 16} {
+		before := runtime.NumGoroutine()
+		var emitted []string
+		err := fetchTables(context.Background(), handler, handler.tables, concurrency, func(index int, table *common.Table) error {
+			if index != len(emitted) {
+				t.Fatalf("emit index %d, want %d", index, len(emitted))
+			}
+			emitted = append(emitted, table.Name)
+			return nil
+		})
+		if err != nil {
+			t.Fatalf("fetchTables error: %v", err)
+		}
+		if len(emitted) != len(handler.tables) {
+			t.Fatalf("emitted %d tables, want %d", len(emitted), len(handler.tables))
+		}
+		for i, name := range emitted {
+			if name != handler.tables[i].Name {
+				t.Fatalf("concurrency %d: table %d emitted as %s, want %s", concurrency, i, name, handler.tables[i].Name)
+			}
+		}
+		checkGoroutines(t, before)
+	}
+}
+
+func TestFetchTablesQueryError(t *testing.T) {
+	handler := delayedHandler(100, time.Millisecond)
+	handler.failing["table_10"] = true
+	// the tables after the failed one are slow, fetchTables must not wait for all of them
+	for i := 11; i < 100; i++ {
+		handler.delays["table_"+strconv.Itoa(i)] = 50 * time.Millisecond
+	}
+	before := runtime.NumGoroutine()
+	start := time.Now()
+	var emitted int
+	err := fetchTables(context.Background(), handler, handler.tables, 4, func(index int, table *common.Table) error {
+		emitted++
+		return nil
+	})
+	if err == nil {
+		t.Fatal("fetchTables should fail")
+	}
+	if emitted != 10 {
+		t.Errorf("emitted %d tables before the failed one, want 10", emitted)
+	}
+	if elapsed := time.Since(start); elapsed > 500*time.Millisecond {
+		t.Errorf("fetchTables returned after %v, want promptly", elapsed)
+	}
+	checkGoroutines(t, before)
+}
+
+func TestFetchTablesEmitError(t *testing.T) {
+	handler := delayedHandler(100, 3*time.Millisecond)
+	emitErr := errors.New("disk full")
+	before := runtime.NumGoroutine()
+	start := time.Now()
+	err := fetchTables(context.Background(), handler, handler.tables, 8, func(index int, table *common.Table) error {
+		if index == 20 {
+			return emitErr
+		}
+		return nil
+	})
+	if !errors.Is(err, emitErr) {
+		t.Fatalf("fetchTables error = %v, want %v", err, emitErr)
+	}
+	if elapsed := time.Since(start); elapsed > 500*time.Millisecond {
+		t.Errorf("fetchTables returned after %v, want promptly", elapsed)
+	}
+	checkGoroutines(t, before)
+}
+
+func TestFetchTablesCancel(t *testing.T) {
+	handler := delayedHandler(100, 3*time.Millisecond)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	before := runtime.NumGoroutine()
+	err := fetchTables(ctx, handler, handler.tables, 4, func(index int, table *common.Table) error {
+		if index == 5 {
+			cancel()
+		}
+		return nil
+	})
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf("fetchTables error = %v, want %v", err, context.Canceled)
+	}
+	checkGoroutines(t, before)
+}
@@ -0,0 +1,205 @@
+// Package generator 读取数据库表结构生成文档，可在其他程序中作为库调用
+//
+//	opts := &generator.Options{Dialselect: "mysql", Host: "127.0.0.1", Port: 3306, ...}
+//	err := generator.Generate(ctx, opts, os.Stdout)
+//
+// 数据库类型及文档格式通过 common.RegisterDialect、common.RegisterRenderer 扩展
+package generator
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"mysql_to_md/common"
+	"regexp"
+
+	// register built-in dialects
+	_ "mysql_to_md/ch"
+	_ "mysql_to_md/mariadb"
+	// register markdown and json renderers
+	_ "mysql_to_md/markdown"
+	_ "mysql_to_md/snapshot"
+)
+
+// Options 生成文档的配置
+type Options = common.Conf
+
+// Generate 连接数据库，读取表结构并按opts.Format格式将文档写入w
+func Generate(ctx context.Context, opts *Options, w io.Writer) error {
+	j, err := prepare(ctx, opts)
+	if err != nil {
+		return err
+	}
+	defer j.close()
+	return j.write(ctx, w, nil)
+}
+
+// job 一次文档生成用到的连接、渲染器及表
+type job struct {
+	opts     *Options
+	handler  common.Handler
+	renderer common.Renderer
+	groups   []common.TableGroup
+	// tables 按分组顺序排列的所有表
+	tables []common.TableInfo
+	// values 从字段注释中解析取值说明的正则
+	values *regexp.Regexp
+	// notes 已有文档中的手写备注，key为表名
+	notes map[string]string
+	// capabilities 数据库类型支持的特性
+	capabilities common.Capabilities
+}
+
+// prepare 连接数据库并查询所有表，返回错误时已关闭连接
+func prepare(ctx context.Context, opts *Options) (j *job, err error) {
+	dialect, ok := common.GetDialect(opts.Dialselect)
+	if !ok {
+		return nil, fmt.Errorf("unsupported dial select %s", opts.Dialselect)
+	}
+	format := opts.Format
+	if format == "" {
+		format = "markdown"
+	}
+	newRenderer, ok := common.GetRenderer(format)
+	if !ok {
+		return nil, fmt.Errorf("unsupported format %s", format)
+	}
+	valuePattern := opts.ValuePattern
+	if valuePattern == "" {
+		valuePattern = common.DefaultValuePattern
+	}
+	values, err := regexp.Compile(valuePattern)
+	if err != nil {
+		return nil, fmt.Errorf("invalid value pattern: %w", err)
+	}
+
+	// connect database service
+	dsn, err := dialect.BuildDSN(opts)
+	if err != nil {
+		return nil, fmt.Errorf("build dsn failed: %w", err)
+	}
+	handler, err := dialect.NewHandler(ctx, opts, dsn)
+	if err != nil {
+		return nil, fmt.Errorf("sql open failed: %w", err)
+	}
+	defer func() {
+		if err != nil {
+			closeHandler(handler)
+		}
+	}()
+	// query all table name
+	tables, err := handler.QueryTables()
+	if err != nil {
+		return nil, fmt.Errorf("query tables of database error: %w", err)
+	}
+
+	// bulk fetch metadata for the whole schema, tables missing from it are queried one by one
+	if prefetcher, ok := handler.(common.Prefetcher); ok {
+		if err = prefetcher.Prefetch(tables); err != nil && opts.Progress != nil {
+			fmt.Fprintf(opts.Progress, "bulk query metadata error, fall back to query table by table, detail is [%v]\n", err.Error())
+		}
+	}
+	annotateTables(tables, opts)
+	if opts.CollapseShards {
+		if tables, err = collapseShards(handler, tables); err != nil {
+			return nil, fmt.Errorf("collapse shards error: %w", err)
+		}
+	}
+	groups, err := groupTables(tables, opts)
+	if err != nil {
+		return nil, err
+	}
+	return &job{opts: opts, handler: handler, renderer: newRenderer(opts), groups: groups, tables: flatten(groups), values: values, capabilities: dialect.Capabilities}, nil
+}
+
+// close 关闭数据库连接
+func (j *job) close() {
+	closeHandler(j.handler)
+}
+
+// closeHandler 关闭实现了 io.Closer 的Handler持有的连接
+func closeHandler(handler common.Handler) {
+	if closer, ok := handler.(io.Closer); ok {
+		closer.Close()
+	}
+}
+
+// tableWriter 将单个表写入单独的输出，render 把表渲染到给定的 io.Writer
+type tableWriter func(table *common.Table, render func(w io.Writer) error) error
+
+// write 逐表查询元数据并渲染写入w，每个表查询完成后立即写出，tableOut不为nil时表写入tableOut
+func (j *job) write(ctx context.Context, w io.Writer, tableOut tableWriter) error {
+	schema := &common.Schema{Database: j.opts.Database, Tables: j.tables, Groups: j.groups, Notes: j.notes, Capabilities: j.capabilities}
+	if extender, ok := j.handler.(common.SchemaExtender); ok {
+		if err := extender.ExtendSchema(schema); err != nil {
+			return fmt.Errorf("extendSchema error: %w", err)
+		}
+	}
+	if err := j.renderer.Begin(w, schema); err != nil {
+		return err
+	}
+	groupEnder, _ := j.renderer.(common.GroupEnder)
+	// groupEnd 结束已输出的最后一个分组
+	groupEnd := func(groupIndex int) error {
+		if groupEnder == nil || groupIndex == 0 {
+			return nil
+		}
+		return groupEnder.GroupEnd(w, &j.groups[groupIndex-1])
+	}
+	// index of the first table of the next group
+	var groupIndex, groupStart int
+	err := fetchTables(ctx, j.handler, j.tables, j.opts.Concurrency, func(index int, table *common.Table) error {
+		for groupIndex < len(j.groups) && index == groupStart {
+			if err := groupEnd(groupIndex); err != nil {
+				return err
+			}
+			if err := j.renderer.Group(w, &j.groups[groupIndex]); err != nil {
+				return err
+			}
+			groupStart += len(j.groups[groupIndex].Tables)
+			groupIndex++
+		}
+		// make content process log
+		if j.opts.Progress != nil {
+			fmt.Fprintf(j.opts.Progress, "%d/%d the %s table is making ...\n", index+1, len(j.tables), table.Name)
+		}
+		table.FedBy = common.Upstream(schema.Lineage, table.Name)
+		table.FeedsInto = common.Downstream(schema.Lineage, table.Name)
+		annotateColumns(table, j.opts)
+		for i := range table.Columns {
+			table.Columns[i].Values = common.ParseValues(&table.Columns[i], j.values)
+		}
+		if tableOut != nil {
+			return tableOut(table, func(tw io.Writer) error {
+				return j.renderer.Table(tw, index, table)
+			})
+		}
+		return j.renderer.Table(w, index, table)
+	})
+	if err != nil {
+		return err
+	}
+	if err = groupEnd(groupIndex); err != nil {
+		return err
+	}
+	return j.renderer.End(w, schema)
+}
+
+// queryTable 查询单个表的字段及建表语句
+func queryTable(handler common.Handler, info common.TableInfo) (*common.Table, error) {
+	columns, err := handler.QueryTableColumn(info.Name)
+	if err != nil {
+		return nil, fmt.Errorf("queryTableColumn %s error: %w", info.Name, err)
+	}
+	createSql, err := handler.QueryCreateSql(info.Name)
+	if err != nil {
+		return nil, fmt.Errorf("queryCreateSql %s error: %w", info.Name, err)
+	}
+	table := &common.Table{TableInfo: info, Columns: columns, CreateSql: createSql}
+	if extender, ok := handler.(common.TableExtender); ok {
+		if err = extender.ExtendTable(table); err != nil {
+			return nil, fmt.Errorf("extendTable %s error: %w", info.Name, err)
+		}
+	}
+	return table, nil
+}
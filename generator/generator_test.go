@@ -0,0 +1,76 @@
+package generator
+
+import (
+	"context"
+	"mysql_to_md/common"
+	"strings"
+	"testing"
+)
+
+// fakeHandlers fake数据库类型按库名返回的Handler
+var fakeHandlers = make(map[string]*fakeHandler)
+
+func init() {
+	common.RegisterDialect(common.Dialect{
+		Name: "fake",
+		BuildDSN: func(conf *common.Conf) (string, error) {
+			return conf.Database, nil
+		},
+		NewHandler: func(ctx context.Context, conf *common.Conf, dsn string) (common.Handler, error) {
+			return fakeHandlers[dsn], nil
+		},
+	})
+}
+
+func TestGenerateClosesHandler(t *testing.T) {
+	handler := &fakeHandler{tables: []common.TableInfo{{Name: "user"}, {Name: "order"}}}
+	fakeHandlers["close"] = handler
+	for i := 1; i <= 2; i++ {
+		var w strings.Builder
+		if err := Generate(context.Background(), &Options{Dialselect: "fake", Database: "close"}, &w); err != nil {
+			t.Fatalf("Generate error: %v", err)
+		}
+		if handler.closed != i {
+			t.Errorf("handler closed %d times after %d runs", handler.closed, i)
+		}
+	}
+
+	handler.failing = map[string]bool{"order": true}
+	var w strings.Builder
+	if err := Generate(context.Background(), &Options{Dialselect: "fake", Database: "close"}, &w); err == nil {
+		t.Fatal("Generate should fail")
+	}
+	if handler.closed != 3 {
+		t.Errorf("handler is not closed after failed run")
+	}
+}
+
+func TestGenerateCollapsedShardsGroupedByPrefix(t *testing.T) {
+	fakeHandlers["shards"] = &fakeHandler{tables: []common.TableInfo{
+		{Name: "order_0"}, {Name: "order_1"}, {Name: "order_2"}, {Name: "user_account"}, {Name: "user_profile"}, {Name: "config"},
+	}}
+	opts := &Options{Dialselect: "fake", Database: "shards", CollapseShards: true, GroupByPrefix: true}
+	groups := moduleTables(t, opts)
+	if got := groups["order"]; len(got) != 1 || got[0] != "order_0" {
+		t.Errorf("module order = %v, want the collapsed order_0", got)
+	}
+	if got := groups[otherModule]; len(got) != 1 || got[0] != "config" {
+		t.Errorf("module %s = %v, want [config]", otherModule, got)
+	}
+}
+
+// moduleTables 按模块分组后各模块中的表
+func moduleTables(t *testing.T, opts *Options) map[string][]string {
+	j, err := prepare(context.Background(), opts)
+	if err != nil {
+		t.Fatalf("prepare error: %v", err)
+	}
+	defer j.close()
+	result := make(map[string][]string)
+	for _, group := range j.groups {
+		for _, table := range group.Tables {
+			result[group.Title] = append(result[group.Title], table.Name)
+		}
+	}
+	return result
+}
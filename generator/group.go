@@ -0,0 +1,144 @@
+package generator
+
+import (
+	"fmt"
+	"mysql_to_md/common"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// otherModule 不属于任何模块的表所在的分组
+const otherModule = "其他"
+
+// groupTables 配置了分组规则或按前缀分组时按模块分组，否则按对象类型分组
+func groupTables(tables []common.TableInfo, opts *Options) ([]common.TableGroup, error) {
+	if len(opts.GroupRules) != 0 {
+		return groupByRules(tables, opts.GroupRules)
+	}
+	if opts.GroupByPrefix {
+		return groupByPrefix(tables), nil
+	}
+	return groupByKind(tables), nil
+}
+
+// groupByRules 按规则的顺序分组，表属于第一个匹配的规则，都不匹配的表放在最后的“其他”分组
+func groupByRules(tables []common.TableInfo, rules []common.GroupRule) ([]common.TableGroup, error) {
+	patterns := make([]*regexp.Regexp, len(rules))
+	for i, rule := range rules {
+		if rule.Pattern == "" {
+			continue
+		}
+		pattern, err := regexp.Compile(rule.Pattern)
+		if err != nil {
+			return nil, fmt.Errorf("invalid pattern of group %s: %w", rule.Name, err)
+		}
+		patterns[i] = pattern
+	}
+	groups := make([]common.TableGroup, len(rules)+1)
+	for i, rule := range rules {
+		groups[i] = common.TableGroup{Title: rule.Name, Description: rule.Description}
+	}
+	groups[len(rules)] = common.TableGroup{Title: otherModule}
+	for _, table := range tables {
+		i := 0
+		for ; i < len(rules); i++ {
+			if rules[i].Prefix != "" && strings.HasPrefix(table.Name, rules[i].Prefix) {
+				break
+			}
+			if patterns[i] != nil && patterns[i].MatchString(table.Name) {
+				break
+			}
+		}
+		groups[i].Tables = append(groups[i].Tables, table)
+	}
+	return nonEmpty(groups), nil
+}
+
+// groupByPrefix 按表名第一个 '_' 及之前的前缀分组，分组按前缀排序，
+// 没有前缀或前缀只有一个表的放在最后的“其他”分组，合并后的分表按成员数计数
+func groupByPrefix(tables []common.TableInfo) []common.TableGroup {
+	byPrefix := make(map[string][]common.TableInfo)
+	counts := make(map[string]int)
+	var prefixes []string
+	for _, table := range tables {
+		prefix := ""
+		if i := strings.Index(table.Name, "_"); i > 0 {
+			prefix = table.Name[:i+1]
+		}
+		if _, ok := byPrefix[prefix]; !ok && prefix != "" {
+			prefixes = append(prefixes, prefix)
+		}
+		byPrefix[prefix] = append(byPrefix[prefix], table)
+		counts[prefix]++
+		if table.Shards != nil && len(table.Shards.Members) > 1 {
+			counts[prefix] += len(table.Shards.Members) - 1
+		}
+	}
+	sort.Strings(prefixes)
+
+	var groups []common.TableGroup
+	others := byPrefix[""]
+	for _, prefix := range prefixes {
+		if counts[prefix] == 1 {
+			others = append(others, byPrefix[prefix]...)
+			continue
+		}
+		groups = append(groups, common.TableGroup{Title: strings.TrimSuffix(prefix, "_"), Tables: byPrefix[prefix]})
+	}
+	return nonEmpty(append(groups, common.TableGroup{Title: otherModule, Tables: others}))
+}
+
+// nonEmpty 去掉没有表的分组
+func nonEmpty(groups []common.TableGroup) []common.TableGroup {
+	var result []common.TableGroup
+	for _, group := range groups {
+		if len(group.Tables) != 0 {
+			result = append(result, group)
+		}
+	}
+	return result
+}
+
+// groupByKind 按对象类型分组，分组按 common.TableKinds 的顺序，
+// 其他类型按出现顺序排在后面，组内保持原有顺序
+func groupByKind(tables []common.TableInfo) []common.TableGroup {
+	byKind := make(map[common.TableKind][]common.TableInfo)
+	kinds := append([]common.TableKind{}, common.TableKinds...)
+	for _, table := range tables {
+		if table.Kind == "" {
+			table.Kind = common.KindTable
+		}
+		if _, ok := byKind[table.Kind]; !ok && !containsKind(common.TableKinds, table.Kind) {
+			kinds = append(kinds, table.Kind)
+		}
+		byKind[table.Kind] = append(byKind[table.Kind], table)
+	}
+
+	var groups []common.TableGroup
+	for _, kind := range kinds {
+		if len(byKind[kind]) == 0 {
+			continue
+		}
+		groups = append(groups, common.TableGroup{Kind: kind, Title: kind.Title(), Tables: byKind[kind]})
+	}
+	return groups
+}
+
+// flatten 按分组顺序展开所有表
+func flatten(groups []common.TableGroup) []common.TableInfo {
+	var tables []common.TableInfo
+	for _, group := range groups {
+		tables = append(tables, group.Tables...)
+	}
+	return tables
+}
+
+func containsKind(kinds []common.TableKind, kind common.TableKind) bool {
+	for _, k := range kinds {
+		if k == kind {
+			return true
+		}
+	}
+	return false
+}
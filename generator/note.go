@@ -0,0 +1,38 @@
+package generator
+
+import (
+	"mysql_to_md/common"
+	"os"
+	"path/filepath"
+)
+
+// readNotes 从已有的文档中读取手写的备注，文档不存在或渲染器不支持时不读取
+func (j *job) readNotes(paths ...string) error {
+	parser, ok := j.renderer.(common.NoteParser)
+	if !ok {
+		return nil
+	}
+	notes := make(map[string]string)
+	for _, path := range paths {
+		file, err := os.Open(path)
+		if os.IsNotExist(err) {
+			continue
+		}
+		if err != nil {
+			return err
+		}
+		err = parser.ParseNotes(file, notes)
+		file.Close()
+		if err != nil {
+			return err
+		}
+	}
+	j.notes = notes
+	return nil
+}
+
+// splitDocuments 分文件输出时已有的目录页及各表的文档
+func splitDocuments(dir string) []string {
+	paths, _ := filepath.Glob(filepath.Join(dir, common.TablesDir, "*.md"))
+	return append([]string{filepath.Join(dir, "index.md")}, paths...)
+}
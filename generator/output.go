@@ -0,0 +1,177 @@
+package generator
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"mysql_to_md/common"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// GenerateFile 生成文档并写入opts.Output，opts.Output为空时按数据库名和当前时间自动命名，
+// opts.Split 时opts.Output为目录，每个表单独一个文件。
+// 文档先流式写入同目录下的临时文件，全部成功后再原子替换目标文件，中途失败不会破坏已有文档
+func GenerateFile(ctx context.Context, opts *Options) error {
+	j, err := prepare(ctx, opts)
+	if err != nil {
+		return err
+	}
+	defer j.close()
+	if opts.Split {
+		return j.writeDir(ctx)
+	}
+	if opts.Output == "" {
+		// automatically generated if no output file path is specified
+		ext := ".md"
+		if extensioner, ok := j.renderer.(common.Extensioner); ok {
+			ext = extensioner.Extension()
+		}
+		opts.Output = opts.Database + "_" + time.Now().Format("20060102_150405") + ext
+	}
+	// keep hand-written notes of the document being replaced
+	if err = j.readNotes(opts.Output); err != nil {
+		return fmt.Errorf("read notes of %s error: %w", opts.Output, err)
+	}
+	return writeFile(opts.Output, func(w *bufio.Writer) error {
+		return j.write(ctx, w, nil)
+	})
+}
+
+// writeDir 每个表写入 opts.Output/tables/<表名>.md，目录页写入 opts.Output/index.md，
+// opts.Output为空时按数据库名和当前时间自动命名，已删除的表对应的文件也会删除。
+// 所有文档写完后才替换已有文档，中途失败时已有文档保持不变
+func (j *job) writeDir(ctx context.Context) error {
+	if format := j.opts.Format; format != "" && format != "markdown" {
+		return fmt.Errorf("split output is not supported by format %s", format)
+	}
+	if j.opts.Output == "" {
+		j.opts.Output = j.opts.Database + "_" + time.Now().Format("20060102_150405")
+	}
+	if err := j.readNotes(splitDocuments(j.opts.Output)...); err != nil {
+		return fmt.Errorf("read notes of %s error: %w", j.opts.Output, err)
+	}
+	tablesDir := filepath.Join(j.opts.Output, common.TablesDir)
+	if err := os.MkdirAll(tablesDir, 0755); err != nil {
+		return err
+	}
+	files := common.TableFileNames(j.tables)
+	folded := make(map[string]bool)
+	for _, name := range files {
+		if folded[strings.ToLower(name)] {
+			return fmt.Errorf("duplicate file name %s of tables", name)
+		}
+		folded[strings.ToLower(name)] = true
+	}
+
+	// all documents are staged in temporary files, and replace the existing ones only when all of them are written
+	var staged []stagedFile
+	defer func() {
+		for _, file := range staged {
+			os.Remove(file.tmp)
+		}
+	}()
+	written := make(map[string]bool)
+	indexFile, err := stageFile(filepath.Join(j.opts.Output, "index.md"), func(w *bufio.Writer) error {
+		return j.write(ctx, w, func(table *common.Table, render func(w io.Writer) error) error {
+			name := files[table.Name]
+			written[name] = true
+			file, err := stageFile(filepath.Join(tablesDir, name), func(tw *bufio.Writer) error {
+				return render(tw)
+			})
+			if err != nil {
+				return err
+			}
+			staged = append(staged, file)
+			return nil
+		})
+	})
+	if err != nil {
+		return err
+	}
+	// index is renamed last, it links to the table documents
+	staged = append(staged, indexFile)
+	for len(staged) > 0 {
+		if err = os.Rename(staged[0].tmp, staged[0].path); err != nil {
+			return err
+		}
+		staged = staged[1:]
+	}
+	return removeStale(tablesDir, written)
+}
+
+// removeStale 删除dir中本次没有生成的文档
+func removeStale(dir string, written map[string]bool) error {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return err
+	}
+	for _, entry := range entries {
+		if entry.IsDir() || filepath.Ext(entry.Name()) != ".md" || written[entry.Name()] {
+			continue
+		}
+		if err = os.Remove(filepath.Join(dir, entry.Name())); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// writeFile 通过临时文件写入path，write成功后才重命名为path
+func writeFile(path string, write func(w *bufio.Writer) error) error {
+	file, err := stageFile(path, write)
+	if err != nil {
+		return err
+	}
+	if err = os.Rename(file.tmp, file.path); err != nil {
+		os.Remove(file.tmp)
+		return err
+	}
+	return nil
+}
+
+// stagedFile 已写完、等待重命名为path的临时文件
+type stagedFile struct {
+	path string
+	tmp  string
+}
+
+// stageFile 将内容写入path同目录下的临时文件，失败时删除临时文件
+func stageFile(path string, write func(w *bufio.Writer) error) (file stagedFile, err error) {
+	tmpFile, err := os.CreateTemp(filepath.Dir(path), "."+filepath.Base(path)+".*.tmp")
+	if err != nil {
+		return file, err
+	}
+	defer func() {
+		if err != nil {
+			tmpFile.Close()
+			os.Remove(tmpFile.Name())
+		}
+	}()
+
+	w := bufio.NewWriter(tmpFile)
+	if err = write(w); err != nil {
+		return file, err
+	}
+	if err = w.Flush(); err != nil {
+		return file, err
+	}
+	if err = tmpFile.Sync(); err != nil {
+		return file, err
+	}
+	// keep the mode of the document being replaced
+	mode := os.FileMode(0644)
+	if info, statErr := os.Stat(path); statErr == nil {
+		mode = info.Mode().Perm()
+	}
+	if err = tmpFile.Chmod(mode); err != nil {
+		return file, err
+	}
+	if err = tmpFile.Close(); err != nil {
+		return file, err
+	}
+	return stagedFile{path: path, tmp: tmpFile.Name()}, nil
+}
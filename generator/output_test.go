@@ -0,0 +1,131 @@
+package generator
+
+import (
+	"context"
+	"errors"
+	"mysql_to_md/common"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+)
+
+// fakeHandler 返回固定表结构的Handler，columns 中没有的表只有一个 id 字段，
+// failing 中的表查询建表语句失败，delays 中的表查询字段前等待
+type fakeHandler struct {
+	tables  []common.TableInfo
+	columns map[string][]common.TableColumn
+	failing map[string]bool
+	delays  map[string]time.Duration
+	closed  int
+}
+
+func (h *fakeHandler) Close() error {
+	h.closed++
+	return nil
+}
+
+func (h *fakeHandler) QueryTables() ([]common.TableInfo, error) {
+	return h.tables, nil
+}
+
+func (h *fakeHandler) QueryTableColumn(tableName string) ([]common.TableColumn, error) {
+	time.Sleep(h.delays[tableName])
+	if columns, ok := h.columns[tableName]; ok {
+		return columns, nil
+	}
+	return []common.TableColumn{{ColumnName: "id", ColumnType: "int"}}, nil
+}
+
+func (h *fakeHandler) QueryCreateSql(tableName string) (string, error) {
+	if h.failing[tableName] {
+		return "", errors.New("connection lost")
+	}
+	return "CREATE TABLE `" + tableName + "` (`id` int)", nil
+}
+
+func newSplitJob(dir string, handler *fakeHandler) *job {
+	opts := &Options{Database: "test", Output: dir, Split: true}
+	newRenderer, _ := common.GetRenderer("markdown")
+	groups := []common.TableGroup{{Kind: common.KindTable, Title: "表", Tables: handler.tables}}
+	return &job{opts: opts, handler: handler, renderer: newRenderer(opts), groups: groups, tables: handler.tables}
+}
+
+// listFiles dir 下所有文件的相对路径及内容
+func listFiles(t *testing.T, dir string) map[string]string {
+	files := make(map[string]string)
+	err := filepath.Walk(dir, func(path string, info os.FileInfo, err error) error {
+		if err != nil || info.IsDir() {
+			return err
+		}
+		content, err := os.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		rel, _ := filepath.Rel(dir, path)
+		files[filepath.ToSlash(rel)] = string(content)
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return files
+}
+
+func TestWriteDirFileNames(t *testing.T) {
+	dir := t.TempDir()
+	handler := &fakeHandler{tables: []common.TableInfo{{Name: "a/b"}, {Name: "a_b"}, {Name: "Order"}, {Name: "order"}}}
+	if err := newSplitJob(dir, handler).writeDir(context.Background()); err != nil {
+		t.Fatalf("writeDir error: %v", err)
+	}
+	var names []string
+	folded := make(map[string]bool)
+	for name := range listFiles(t, filepath.Join(dir, common.TablesDir)) {
+		if folded[strings.ToLower(name)] {
+			t.Errorf("file name %s collides with another table", name)
+		}
+		folded[strings.ToLower(name)] = true
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	if len(names) != len(handler.tables) {
+		t.Errorf("table files = %v, want one file per table", names)
+	}
+	index, err := os.ReadFile(filepath.Join(dir, "index.md"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for name, file := range common.TableFileNames(handler.tables) {
+		if !strings.Contains(string(index), common.TablesDir+"/"+file) {
+			t.Errorf("index does not link table %s to %s", name, file)
+		}
+	}
+}
+
+func TestWriteDirFailureKeepsExistingDocuments(t *testing.T) {
+	dir := t.TempDir()
+	tables := []common.TableInfo{{Name: "user"}, {Name: "order"}, {Name: "item"}}
+	if err := newSplitJob(dir, &fakeHandler{tables: tables}).writeDir(context.Background()); err != nil {
+		t.Fatalf("writeDir error: %v", err)
+	}
+	before := listFiles(t, dir)
+
+	// the last table fails after the others are rendered
+	commented := []common.TableInfo{{Name: "user"}, {Name: "order"}, {Name: "item"}}
+	commented[0].Comment.String, commented[0].Comment.Valid = "用户", true
+	handler := &fakeHandler{tables: commented, failing: map[string]bool{"item": true}}
+	if err := newSplitJob(dir, handler).writeDir(context.Background()); err == nil {
+		t.Fatal("writeDir should fail")
+	}
+	after := listFiles(t, dir)
+	if len(after) != len(before) {
+		t.Errorf("files after failed run = %d, want %d without temporary files", len(after), len(before))
+	}
+	for name, content := range before {
+		if after[name] != content {
+			t.Errorf("%s is changed by the failed run", name)
+		}
+	}
+}
@@ -0,0 +1,194 @@
+package generator
+
+import (
+	"fmt"
+	"mysql_to_md/common"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// shardSuffix 分表名称，前缀加数字或日期后缀，如 order_0、log_202401
+var shardSuffix = regexp.MustCompile(`^(.+?)(\d+)$`)
+
+// shardMember 分表中的一个表
+type shardMember struct {
+	info    common.TableInfo
+	suffix  string
+	columns []common.TableColumn
+}
+
+// collapseShards 将前缀相同、后缀为数字且字段结构相同的表合并为一个，合并后的表在第一个成员的位置输出。
+// 以成员中最多的结构为准，结构不同的成员仍单独输出，并记录在合并后的表上
+func collapseShards(handler common.Handler, tables []common.TableInfo) ([]common.TableInfo, error) {
+	var prefixes []string
+	families := make(map[string][]*shardMember)
+	for _, table := range tables {
+		match := shardSuffix.FindStringSubmatch(table.Name)
+		if match == nil || (table.Kind != "" && table.Kind != common.KindTable) {
+			continue
+		}
+		if _, ok := families[match[1]]; !ok {
+			prefixes = append(prefixes, match[1])
+		}
+		families[match[1]] = append(families[match[1]], &shardMember{info: table, suffix: match[2]})
+	}
+
+	// representative table of each family, and tables merged into it
+	representatives := make(map[string]*common.ShardFamily)
+	merged := make(map[string]bool)
+	for _, prefix := range prefixes {
+		members := families[prefix]
+		if len(members) < 2 {
+			continue
+		}
+		sort.SliceStable(members, func(i, j int) bool {
+			if len(members[i].suffix) != len(members[j].suffix) {
+				return len(members[i].suffix) < len(members[j].suffix)
+			}
+			return members[i].suffix < members[j].suffix
+		})
+		// count members of each structure, the most common one is the structure of the family
+		counts := make(map[string]int)
+		signatures := make([]string, len(members))
+		for i, member := range members {
+			columns, err := handler.QueryTableColumn(member.info.Name)
+			if err != nil {
+				return nil, fmt.Errorf("queryTableColumn %s error: %w", member.info.Name, err)
+			}
+			member.columns = columns
+			signatures[i] = columnSignature(columns)
+			counts[signatures[i]]++
+		}
+		var family int
+		for i, signature := range signatures {
+			if counts[signature] > counts[signatures[family]] {
+				family = i
+			}
+		}
+		if counts[signatures[family]] < 2 {
+			continue
+		}
+		shards := &common.ShardFamily{}
+		var suffixes []string
+		for i, member := range members {
+			if signatures[i] != signatures[family] {
+				shards.Differs = append(shards.Differs, common.ShardDiff{
+					Table:   member.info.Name,
+					Reasons: diffColumns(members[family].columns, member.columns),
+				})
+				continue
+			}
+			if len(suffixes) == 0 {
+				representatives[member.info.Name] = shards
+			} else {
+				merged[member.info.Name] = true
+			}
+			suffixes = append(suffixes, member.suffix)
+			shards.Members = append(shards.Members, member.info.Name)
+		}
+		shards.Pattern = shardPattern(prefix, suffixes)
+	}
+
+	var result []common.TableInfo
+	for _, table := range tables {
+		if merged[table.Name] {
+			continue
+		}
+		if shards, ok := representatives[table.Name]; ok {
+			table.Shards = shards
+		}
+		result = append(result, table)
+	}
+	return result, nil
+}
+
+// shardPattern 分表名称的模式，连续的后缀合并为范围，不连续时逐段列出，
+// 如 order_{0..3,5,7..9}，后缀按数值排序
+func shardPattern(prefix string, suffixes []string) string {
+	var parts []string
+	for start := 0; start < len(suffixes); {
+		end := start
+		for end+1 < len(suffixes) && nextSuffix(suffixes[end], suffixes[end+1]) {
+			end++
+		}
+		switch end - start {
+		case 0:
+			parts = append(parts, suffixes[start])
+		case 1:
+			parts = append(parts, suffixes[start], suffixes[end])
+		default:
+			parts = append(parts, suffixes[start]+".."+suffixes[end])
+		}
+		start = end + 1
+	}
+	return prefix + "{" + strings.Join(parts, ",") + "}"
+}
+
+// nextSuffix next 是否紧接在 suffix 之后，补零的后缀位数需相同，如 09 之后为 10
+func nextSuffix(suffix, next string) bool {
+	value, err := strconv.ParseUint(suffix, 10, 64)
+	if err != nil {
+		return false
+	}
+	nextValue, err := strconv.ParseUint(next, 10, 64)
+	if err != nil || nextValue != value+1 {
+		return false
+	}
+	return len(next) == len(suffix) || (suffix[0] != '0' && next[0] != '0')
+}
+
+// columnSignature 字段结构，不含注释
+func columnSignature(columns []common.TableColumn) string {
+	var signature string
+	for _, column := range columns {
+		signature += fmt.Sprintf("%s %s %s %s %v %q %s\n", column.ColumnName, column.ColumnType, column.IsNullable,
+			column.ColumnKey.String, column.ColumnDefault.Valid, column.ColumnDefault.String, column.Extra.String)
+	}
+	return signature
+}
+
+// diffColumns 成员相对分表结构的差异
+func diffColumns(expected, actual []common.TableColumn) []string {
+	var reasons []string
+	actualByName := make(map[string]common.TableColumn)
+	for _, column := range actual {
+		actualByName[column.ColumnName] = column
+	}
+	expectedByName := make(map[string]bool)
+	for i, column := range expected {
+		expectedByName[column.ColumnName] = true
+		other, ok := actualByName[column.ColumnName]
+		switch {
+		case !ok:
+			reasons = append(reasons, "缺少字段 "+column.ColumnName)
+		case columnSignature(expected[i:i+1]) != columnSignature([]common.TableColumn{other}):
+			reasons = append(reasons, fmt.Sprintf("字段 %s 为 %s", column.ColumnName, describeColumn(other)))
+		}
+	}
+	for _, column := range actual {
+		if !expectedByName[column.ColumnName] {
+			reasons = append(reasons, "多出字段 "+column.ColumnName)
+		}
+	}
+	if len(reasons) == 0 {
+		reasons = append(reasons, "字段顺序不同")
+	}
+	return reasons
+}
+
+// describeColumn 字段的类型、是否允许空及默认值
+func describeColumn(column common.TableColumn) string {
+	description := column.ColumnType
+	if column.IsNullable == "NO" {
+		description += " NOT NULL"
+	}
+	if column.ColumnDefault.Valid {
+		description += " DEFAULT " + column.ColumnDefault.String
+	}
+	if column.Extra.String != "" {
+		description += " " + column.Extra.String
+	}
+	return description
+}
@@ -0,0 +1,79 @@
+package generator
+
+import (
+	"mysql_to_md/common"
+	"reflect"
+	"testing"
+)
+
+func TestShardPattern(t *testing.T) {
+	cases := []struct {
+		suffixes []string
+		pattern  string
+	}{
+		{[]string{"0", "1", "2", "3"}, "order_{0..3}"},
+		{[]string{"0", "1", "10"}, "order_{0,1,10}"},
+		{[]string{"0", "1", "2", "5", "7", "8", "9"}, "order_{0..2,5,7..9}"},
+		{[]string{"8", "9", "10", "11"}, "order_{8..11}"},
+		{[]string{"00", "01", "02", "10"}, "order_{00..02,10}"},
+		{[]string{"1", "2", "02"}, "order_{1,2,02}"},
+	}
+	for _, c := range cases {
+		if pattern := shardPattern("order_", c.suffixes); pattern != c.pattern {
+			t.Errorf("shardPattern(%v) = %q, want %q", c.suffixes, pattern, c.pattern)
+		}
+	}
+}
+
+func TestCollapseShards(t *testing.T) {
+	id := common.TableColumn{ColumnName: "id", ColumnType: "int"}
+	amount := common.TableColumn{ColumnName: "amount", ColumnType: "decimal(10,2)"}
+	handler := &fakeHandler{
+		tables: []common.TableInfo{
+			{Name: "user"},
+			{Name: "order_0"}, {Name: "order_1"}, {Name: "order_2"}, {Name: "order_3"},
+			{Name: "log_1"},
+			{Name: "tb_1"}, {Name: "tb_2"},
+			{Name: "v_order_1", Kind: common.KindView}, {Name: "v_order_2", Kind: common.KindView},
+		},
+		columns: map[string][]common.TableColumn{
+			"order_0": {id, amount},
+			"order_1": {id, amount},
+			"order_2": {id},
+			"order_3": {id, amount},
+			"tb_1":    {id, amount},
+		},
+	}
+	tables, err := collapseShards(handler, handler.tables)
+	if err != nil {
+		t.Fatalf("collapseShards error: %v", err)
+	}
+	var names []string
+	for _, table := range tables {
+		names = append(names, table.Name)
+	}
+	// log has one member, members of tb all differ, and views are not shards
+	want := []string{"user", "order_0", "order_2", "log_1", "tb_1", "tb_2", "v_order_1", "v_order_2"}
+	if !reflect.DeepEqual(names, want) {
+		t.Fatalf("tables = %v, want %v", names, want)
+	}
+	for _, table := range tables {
+		if table.Name != "order_0" && table.Shards != nil {
+			t.Errorf("table %s should not be collapsed", table.Name)
+		}
+	}
+
+	shards := tables[1].Shards
+	if shards == nil {
+		t.Fatal("order_0 is not collapsed")
+	}
+	if shards.Pattern != "order_{0,1,3}" {
+		t.Errorf("pattern = %q, want order_{0,1,3}", shards.Pattern)
+	}
+	if !reflect.DeepEqual(shards.Members, []string{"order_0", "order_1", "order_3"}) {
+		t.Errorf("members = %v, want [order_0 order_1 order_3]", shards.Members)
+	}
+	if len(shards.Differs) != 1 || shards.Differs[0].Table != "order_2" || len(shards.Differs[0].Reasons) == 0 {
+		t.Errorf("differs = %+v, want order_2 with reasons", shards.Differs)
+	}
+}
@@ -3,15 +3,16 @@ module mysql_to_md
 go 1.17
 
 require (
+	github.com/ClickHouse/clickhouse-go v1.4.5
+	github.com/go-sql-driver/mysql v1.6.0
+	gopkg.in/yaml.v3 v3.0.1
 	gorm.io/driver/clickhouse v0.2.2
 	gorm.io/driver/mysql v1.2.2
 	gorm.io/gorm v1.22.4
 )
 
 require (
-	github.com/ClickHouse/clickhouse-go v1.4.5 // indirect
 	github.com/cloudflare/golz4 v0.0.0-20150217214814-ef862a3cdc58 // indirect
-	github.com/go-sql-driver/mysql v1.6.0 // indirect
 	github.com/hashicorp/go-version v1.3.0 // indirect
 	github.com/jinzhu/inflection v1.0.0 // indirect
 	github.com/jinzhu/now v1.1.3 // indirect
@@ -0,0 +1,65 @@
+package mariadb
+
+import (
+	"fmt"
+	"mysql_to_md/common"
+)
+
+const (
+	// SqlSchemaColumn 批量查看数据库所有数据表列信息SQL
+	SqlSchemaColumn = "SELECT `TABLE_NAME`,%s FROM `information_schema`.`columns` WHERE `table_schema`='%s'%s ORDER BY `TABLE_NAME`,`ORDINAL_POSITION` ASC"
+)
+
+// Prefetch 一次查询整个库的列信息、分区及约束并按表分组，替代逐表查询
+func (m *Mariadb) Prefetch(tables []common.TableInfo) error {
+	columns, err := m.querySchemaColumn(tableFilter(m.Conf, tables))
+	if err != nil {
+		return err
+	}
+	m.columns = columns
+
+	partitions, err := m.queryPartition(tableFilter(m.Conf, tables))
+	if err != nil {
+		return err
+	}
+	m.partitions = partitions
+
+	constraints, err := m.queryConstraint(tableFilter(m.Conf, tables))
+	if err != nil {
+		return err
+	}
+	m.constraints = constraints
+	return nil
+}
+
+// tableFilter 指定了表过滤时只查询选中的表
+func tableFilter(conf *common.Conf, tables []common.TableInfo) string {
+	if conf.Tables == "" {
+		return ""
+	}
+	names := make([]string, 0, len(tables))
+	for _, table := range tables {
+		names = append(names, table.Name)
+	}
+	return " AND `TABLE_NAME` IN (" + common.QuoteList(names) + ")"
+}
+
+// querySchemaColumn 批量查询列信息，filter为附加的过滤条件
+func (m *Mariadb) querySchemaColumn(filter string) (map[string][]common.TableColumn, error) {
+	rows, err := m.DB.Raw(fmt.Sprintf(SqlSchemaColumn, m.columnFields(), m.Conf.Database, filter)).Rows()
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	columns := make(map[string][]common.TableColumn)
+	for rows.Next() {
+		var tableName string
+		var column common.TableColumn
+		err = rows.Scan(append([]interface{}{&tableName}, columnDest(&column)...)...)
+		if err != nil {
+			return nil, err
+		}
+		columns[tableName] = append(columns[tableName], column)
+	}
+	return columns, rows.Err()
+}
@@ -0,0 +1,77 @@
+package mariadb
+
+import (
+	"fmt"
+	"mysql_to_md/common"
+	"strings"
+)
+
+const (
+	// SqlFieldsOfTable 查看 information_schema 中的表有哪些字段，用于兼容不同版本
+	SqlFieldsOfTable = "SELECT `COLUMN_NAME` FROM `information_schema`.`columns` WHERE `table_schema`='information_schema' AND `table_name`='%s'"
+)
+
+// baseColumnFields 所有版本都有的列信息字段
+var baseColumnFields = []string{"ORDINAL_POSITION", "COLUMN_NAME", "COLUMN_TYPE", "COLUMN_KEY", "IS_NULLABLE", "COLUMN_COMMENT", "COLUMN_DEFAULT"}
+
+// extraColumnFields 较新版本才有的列信息字段，如 GENERATION_EXPRESSION 需要 mysql 5.7，SRS_ID 需要 mysql 8.0
+var extraColumnFields = []string{"EXTRA", "CHARACTER_SET_NAME", "COLLATION_NAME", "GENERATION_EXPRESSION", "SRS_ID"}
+
+// columnFields 列信息查询字段，与 columnDest 的顺序一致，当前版本没有的字段查询为NULL
+func (m *Mariadb) columnFields() string {
+	m.fieldsOnce.Do(func() {
+		available, err := m.queryFieldsOf("COLUMNS")
+		if err != nil {
+			m.Conf.Logf("execute query columns of information_schema.columns error,had ignored, detail is [%v]\n", err.Error())
+		}
+		var fields []string
+		for _, field := range baseColumnFields {
+			fields = append(fields, "`"+field+"`")
+		}
+		for _, field := range extraColumnFields {
+			if available[field] {
+				fields = append(fields, "`"+field+"`")
+			} else {
+				fields = append(fields, "NULL")
+			}
+		}
+		m.fields = strings.Join(fields, ",")
+	})
+	return m.fields
+}
+
+// queryFieldsOf 查询 information_schema 中表的字段，字段名为大写，表不存在时为空
+func (m *Mariadb) queryFieldsOf(table string) (map[string]bool, error) {
+	rows, err := m.DB.Raw(fmt.Sprintf(SqlFieldsOfTable, table)).Rows()
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	available := make(map[string]bool)
+	for rows.Next() {
+		var name string
+		if err = rows.Scan(&name); err != nil {
+			return nil, err
+		}
+		available[strings.ToUpper(name)] = true
+	}
+	return available, rows.Err()
+}
+
+// columnDest 与 columnFields 顺序一致的列信息扫描目标
+func columnDest(column *common.TableColumn) []interface{} {
+	return []interface{}{
+		&column.OrdinalPosition,
+		&column.ColumnName,
+		&column.ColumnType,
+		&column.ColumnKey,
+		&column.IsNullable,
+		&column.ColumnComment,
+		&column.ColumnDefault,
+		&column.Extra,
+		&column.CharacterSet,
+		&column.Collation,
+		&column.GenerationExpression,
+		&column.SrsID,
+	}
+}
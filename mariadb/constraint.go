@@ -0,0 +1,141 @@
+package mariadb
+
+import (
+	"database/sql"
+	"fmt"
+	"mysql_to_md/common"
+)
+
+const (
+	// SqlTableConstraint 查看数据表约束SQL，ENFORCED 需要 mysql 8.0.16
+	SqlTableConstraint = "SELECT `TABLE_NAME`,`CONSTRAINT_NAME`,`CONSTRAINT_TYPE`,%s FROM `information_schema`.`TABLE_CONSTRAINTS` WHERE `TABLE_SCHEMA`='%s'%s ORDER BY `TABLE_NAME`,FIELD(`CONSTRAINT_TYPE`,'PRIMARY KEY','UNIQUE','FOREIGN KEY','CHECK'),`CONSTRAINT_NAME`"
+	// SqlKeyColumnUsage 查看约束包含的字段及外键引用
+	SqlKeyColumnUsage = "SELECT `TABLE_NAME`,`CONSTRAINT_NAME`,`COLUMN_NAME`,`REFERENCED_TABLE_SCHEMA`,`REFERENCED_TABLE_NAME`,`REFERENCED_COLUMN_NAME` FROM `information_schema`.`KEY_COLUMN_USAGE` WHERE `TABLE_SCHEMA`='%s'%s ORDER BY `TABLE_NAME`,`CONSTRAINT_NAME`,`ORDINAL_POSITION`"
+	// SqlCheckConstraint 查看CHECK约束表达式，需要 mysql 8.0.16 或 mariadb 10.2，mysql 没有 TABLE_NAME 字段
+	SqlCheckConstraint = "SELECT %s,`CONSTRAINT_NAME`,`CHECK_CLAUSE` FROM `information_schema`.`CHECK_CONSTRAINTS` WHERE `CONSTRAINT_SCHEMA`='%s'"
+)
+
+// extendConstraint 补充表的约束
+func (m *Mariadb) extendConstraint(table *common.Table) error {
+	if m.constraints != nil {
+		table.Constraints = m.constraints[table.Name]
+		return nil
+	}
+	constraints, err := m.queryConstraint(" AND `TABLE_NAME`=" + common.QuoteList([]string{table.Name}))
+	if err != nil {
+		m.Conf.Logf("execute query table constraint error, detail is [%v]\n", err.Error())
+		return err
+	}
+	table.Constraints = constraints[table.Name]
+	return nil
+}
+
+// queryConstraint 查询约束及其字段、外键引用和CHECK表达式，filter为附加的过滤条件
+func (m *Mariadb) queryConstraint(filter string) (map[string][]common.Constraint, error) {
+	enforced := "NULL"
+	if m.constraintFields()["ENFORCED"] {
+		enforced = "`ENFORCED`"
+	}
+	rows, err := m.DB.Raw(fmt.Sprintf(SqlTableConstraint, enforced, m.Conf.Database, filter)).Rows()
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	constraints := make(map[string][]common.Constraint)
+	// position of constraint in constraints[table], key is table and constraint name
+	positions := make(map[[2]string]int)
+	for rows.Next() {
+		var tableName string
+		var constraint common.Constraint
+		var enforced sql.NullString
+		if err = rows.Scan(&tableName, &constraint.Name, &constraint.Type, &enforced); err != nil {
+			return nil, err
+		}
+		constraint.Enforced = enforced.String
+		positions[[2]string{tableName, constraint.Name}] = len(constraints[tableName])
+		constraints[tableName] = append(constraints[tableName], constraint)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	if err = m.queryKeyColumnUsage(filter, constraints, positions); err != nil {
+		return nil, err
+	}
+	if err = m.queryCheckClause(constraints, positions); err != nil {
+		m.Conf.Logf("execute query check constraint error,had ignored, detail is [%v]\n", err.Error())
+	}
+	return constraints, nil
+}
+
+// queryKeyColumnUsage 补充约束包含的字段及外键引用的表和字段
+func (m *Mariadb) queryKeyColumnUsage(filter string, constraints map[string][]common.Constraint, positions map[[2]string]int) error {
+	rows, err := m.DB.Raw(fmt.Sprintf(SqlKeyColumnUsage, m.Conf.Database, filter)).Rows()
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var tableName, name, column string
+		var refSchema, refTable, refColumn sql.NullString
+		if err = rows.Scan(&tableName, &name, &column, &refSchema, &refTable, &refColumn); err != nil {
+			return err
+		}
+		i, ok := positions[[2]string{tableName, name}]
+		if !ok {
+			continue
+		}
+		constraint := &constraints[tableName][i]
+		constraint.Columns = append(constraint.Columns, column)
+		if refTable.Valid {
+			constraint.ReferencedTable = m.tableName(refSchema.String, refTable.String)
+			constraint.ReferencedColumns = append(constraint.ReferencedColumns, refColumn.String)
+		}
+	}
+	return rows.Err()
+}
+
+// queryCheckClause 补充CHECK约束的表达式，不支持CHECK约束的版本不查询
+func (m *Mariadb) queryCheckClause(constraints map[string][]common.Constraint, positions map[[2]string]int) error {
+	fields, err := m.queryFieldsOf("CHECK_CONSTRAINTS")
+	if err != nil || len(fields) == 0 {
+		return err
+	}
+	tableField := "''"
+	if fields["TABLE_NAME"] {
+		tableField = "`TABLE_NAME`"
+	}
+	rows, err := m.DB.Raw(fmt.Sprintf(SqlCheckConstraint, tableField, m.Conf.Database)).Rows()
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var tableName, name, clause string
+		if err = rows.Scan(&tableName, &name, &clause); err != nil {
+			return err
+		}
+		for table := range constraints {
+			// check constraint names are unique in a schema on mysql, table name is given on mariadb
+			if tableName != "" && tableName != table {
+				continue
+			}
+			if i, ok := positions[[2]string{table, name}]; ok && constraints[table][i].Type == "CHECK" {
+				constraints[table][i].Expression = clause
+			}
+		}
+	}
+	return rows.Err()
+}
+
+// constraintFields information_schema.TABLE_CONSTRAINTS 的字段，只查询一次
+func (m *Mariadb) constraintFields() map[string]bool {
+	m.constraintFieldsOnce.Do(func() {
+		fields, err := m.queryFieldsOf("TABLE_CONSTRAINTS")
+		if err != nil {
+			m.Conf.Logf("execute query columns of information_schema.TABLE_CONSTRAINTS error,had ignored, detail is [%v]\n", err.Error())
+		}
+		m.tableConstraintFields = fields
+	})
+	return m.tableConstraintFields
+}
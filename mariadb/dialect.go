@@ -0,0 +1,48 @@
+package mariadb
+
+import (
+	"context"
+	"mysql_to_md/common"
+
+	"gorm.io/driver/mysql"
+	"gorm.io/gorm"
+)
+
+func init() {
+	common.RegisterDialect(common.Dialect{
+		Name:        "mysql",
+		Description: "MySQL / MariaDB",
+		BuildDSN:    BuildDSN,
+		NewHandler:  New,
+		Capabilities: common.Capabilities{
+			Comments:    true,
+			ForeignKeys: true,
+		},
+	})
+}
+
+// New 连接mysql并创建Mariadb
+func New(ctx context.Context, conf *common.Conf, dsn string) (common.Handler, error) {
+	db, err := gorm.Open(mysql.Open(dsn), &gorm.Config{})
+	if err != nil {
+		return nil, err
+	}
+	// keep enough idle connections for concurrent metadata queries
+	sqlDB, err := db.DB()
+	if err != nil {
+		return nil, err
+	}
+	if conf.Concurrency > 1 {
+		sqlDB.SetMaxIdleConns(conf.Concurrency)
+	}
+	return &Mariadb{DB: db.WithContext(ctx), Conf: conf}, nil
+}
+
+// Close 关闭连接池，生成文档后由generator调用
+func (m *Mariadb) Close() error {
+	sqlDB, err := m.DB.DB()
+	if err != nil {
+		return err
+	}
+	return sqlDB.Close()
+}
@@ -5,7 +5,7 @@ import (
 	"fmt"
 	"mysql_to_md/common"
 	"regexp"
-	"strings"
+	"sync"
 
 	"gorm.io/gorm"
 )
@@ -14,15 +14,34 @@ import (
 type Mariadb struct {
 	DB   *gorm.DB
 	Conf *common.Conf
+
+	// columns 批量查询的列信息，key为表名
+	columns map[string][]common.TableColumn
+	// kinds 表的对象类型，key为表名
+	kinds map[string]common.TableKind
+	// triggers 表上的触发器，key为表名
+	triggers map[string][]common.Trigger
+	// partitions 批量查询的分区，key为表名，批量查询后为非nil
+	partitions map[string]*common.Partitioning
+	// constraints 批量查询的约束，key为表名，批量查询后为非nil
+	constraints map[string][]common.Constraint
+	// tableConstraintFields information_schema.TABLE_CONSTRAINTS 的字段，按数据库版本查询一次
+	tableConstraintFields map[string]bool
+	constraintFieldsOnce  sync.Once
+	// fields 列信息查询字段，按数据库版本生成一次
+	fields     string
+	fieldsOnce sync.Once
 }
 
 const (
 	// SqlTables 查看数据库所有数据表SQL
-	SqlTables = "SELECT `table_name`,`table_comment` FROM `information_schema`.`tables` WHERE `table_schema`='%s'"
+	SqlTables = "SELECT `table_name`,`table_comment`,`table_type`,`engine`,`table_collation`,`row_format`,`table_rows`,`data_length`,`index_length`,`auto_increment`,`create_time`,`update_time` FROM `information_schema`.`tables` WHERE `table_schema`='%s'"
 	// SqlTableColumn 查看数据表列信息SQL
-	SqlTableColumn = "SELECT `ORDINAL_POSITION`,`COLUMN_NAME`,`COLUMN_TYPE`,`COLUMN_KEY`,`IS_NULLABLE`,`COLUMN_COMMENT`,`COLUMN_DEFAULT` FROM `information_schema`.`columns` WHERE `table_schema`='%s' AND `table_name`='%s' ORDER BY `ORDINAL_POSITION` ASC"
-	// SqlTableCreate 查看建表语句
+	SqlTableColumn = "SELECT %s FROM `information_schema`.`columns` WHERE `table_schema`='%s' AND `table_name`='%s' ORDER BY `ORDINAL_POSITION` ASC"
+	// SqlTableCreate 查看建表语句，表名需经 quoteName 转义
 	SqlTableCreate = "SHOW CREATE TABLE %s"
+	// SqlViewCreate 查看视图创建语句，视图名需经 quoteName 转义
+	SqlViewCreate = "SHOW CREATE VIEW %s"
 )
 
 type tableCreateSql struct {
@@ -30,53 +49,63 @@ type tableCreateSql struct {
 	CreateSql string `db:"Create Table"`
 }
 
+type viewCreateSql struct {
+	View                string `db:"View"`
+	CreateSql           string `db:"Create View"`
+	CharacterSetClient  string `db:"character_set_client"`
+	CollationConnection string `db:"collation_connection"`
+}
+
 // QueryTables 查询所有表
 func (m *Mariadb) QueryTables() ([]common.TableInfo, error) {
 	var tableCollect []common.TableInfo
-	var tableArray []string
-	var commentArray []sql.NullString
 
-	fmt.Println(fmt.Sprintf(SqlTables, m.Conf.Database))
 	rows, err := m.DB.Raw(fmt.Sprintf(SqlTables, m.Conf.Database)).Rows()
 	if err != nil {
 		return tableCollect, err
 	}
+	defer rows.Close()
 
+	m.kinds = make(map[string]common.TableKind)
 	for rows.Next() {
 		var info common.TableInfo
-		err = rows.Scan(&info.Name, &info.Comment)
+		var tableType string
+		// engine, collation and row format are null for views
+		var engine, collation, rowFormat sql.NullString
+		err = rows.Scan(
+			&info.Name,
+			&info.Comment,
+			&tableType,
+			&engine,
+			&collation,
+			&rowFormat,
+			&info.Rows,
+			&info.DataLength,
+			&info.IndexLength,
+			&info.AutoIncrement,
+			&info.CreateTime,
+			&info.UpdateTime)
 		if err != nil {
-			fmt.Printf("execute query tables action error,had ignored, detail is [%v]\n", err.Error())
+			m.Conf.Logf("execute query tables action error,had ignored, detail is [%v]\n", err.Error())
 			continue
 		}
+		info.Engine = engine.String
+		info.Collation = collation.String
+		info.RowFormat = rowFormat.String
+		if tableType == "VIEW" || tableType == "SYSTEM VIEW" {
+			info.Kind = common.KindView
+			// mysql fills the comment of views with 'VIEW'
+			if info.Comment.String == "VIEW" {
+				info.Comment.String = ""
+			}
+		}
+		m.kinds[info.Name] = info.Kind
 
 		tableCollect = append(tableCollect, info)
-		tableArray = append(tableArray, info.Name)
-		commentArray = append(commentArray, info.Comment)
 	}
 	// filter tables when specified tables params
 	if m.Conf.Tables != "" {
-		tableCollect = nil
-		chooseTables := strings.Split(m.Conf.Tables, ",")
-		indexMap := make(map[int]int)
-		for _, item := range chooseTables {
-			subIndexMap := common.GetTargetIndexMap(tableArray, item)
-			for k, v := range subIndexMap {
-				if _, ok := indexMap[k]; ok {
-					continue
-				}
-				indexMap[k] = v
-			}
-		}
-
-		if len(indexMap) != 0 {
-			for _, v := range indexMap {
-				var info common.TableInfo
-				info.Name = tableArray[v]
-				info.Comment = commentArray[v]
-				tableCollect = append(tableCollect, info)
-			}
-		}
+		tableCollect = common.FilterTables(tableCollect, m.Conf.Tables)
 	}
 
 	return tableCollect, err
@@ -84,28 +113,26 @@ func (m *Mariadb) QueryTables() ([]common.TableInfo, error) {
 
 // QueryTableColumn 查询表字段
 func (m *Mariadb) QueryTableColumn(tableName string) ([]common.TableColumn, error) {
+	// 优先使用批量查询结果
+	if columns, ok := m.columns[tableName]; ok {
+		return columns, nil
+	}
 	// 定义承载列信息的切片
 	var columns []common.TableColumn
 
 	querySql := SqlTableColumn
 
-	rows, err := m.DB.Raw(fmt.Sprintf(querySql, m.Conf.Database, tableName)).Rows()
+	rows, err := m.DB.Raw(fmt.Sprintf(querySql, m.columnFields(), m.Conf.Database, tableName)).Rows()
 	if err != nil {
-		fmt.Printf("execute query table column action error, detail is [%v]\n", err.Error())
+		m.Conf.Logf("execute query table column action error, detail is [%v]\n", err.Error())
 		return columns, err
 	}
+	defer rows.Close()
 	for rows.Next() {
 		var column common.TableColumn
-		err = rows.Scan(
-			&column.OrdinalPosition,
-			&column.ColumnName,
-			&column.ColumnType,
-			&column.ColumnKey,
-			&column.IsNullable,
-			&column.ColumnComment,
-			&column.ColumnDefault)
+		err = rows.Scan(columnDest(&column)...)
 		if err != nil {
-			fmt.Printf("query table column scan error, detail is [%v]\n", err.Error())
+			m.Conf.Logf("query table column scan error, detail is [%v]\n", err.Error())
 			return columns, err
 		}
 		columns = append(columns, column)
@@ -116,17 +143,35 @@ func (m *Mariadb) QueryTableColumn(tableName string) ([]common.TableColumn, erro
 
 // QueryCreateSql 查询建表语句
 func (m *Mariadb) QueryCreateSql(tableName string) (string, error) {
+	if m.kinds[tableName] == common.KindView {
+		return m.queryCreateView(tableName)
+	}
 	var createSql tableCreateSql
 	var err error
-	rows, err := m.DB.Raw(fmt.Sprintf(SqlTableCreate, tableName)).Rows()
-	for rows.Next() {
-		rows.Scan(&createSql.Table, &createSql.CreateSql)
-	}
+	rows, err := m.DB.Raw(fmt.Sprintf(SqlTableCreate, quoteName(tableName))).Rows()
 	if err != nil {
-		fmt.Printf("execute query table create sql error, detail is [%v]\n", err.Error())
+		m.Conf.Logf("execute query table create sql error, detail is [%v]\n", err.Error())
 		return "", err
 	}
+	defer rows.Close()
+	for rows.Next() {
+		rows.Scan(&createSql.Table, &createSql.CreateSql)
+	}
 	reg := regexp.MustCompile(`AUTO_INCREMENT=\d+ `)
 	res := reg.ReplaceAllString(createSql.CreateSql, "")
 	return res, nil
 }
+
+// ExtendTable 补充表的分区、约束及表上的触发器
+func (m *Mariadb) ExtendTable(table *common.Table) error {
+	if table.Kind != common.KindView {
+		if err := m.extendPartition(table); err != nil {
+			return err
+		}
+		if err := m.extendConstraint(table); err != nil {
+			return err
+		}
+	}
+	table.Triggers = m.triggers[table.Name]
+	return nil
+}
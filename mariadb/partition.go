@@ -0,0 +1,64 @@
+package mariadb
+
+import (
+	"database/sql"
+	"fmt"
+	"mysql_to_md/common"
+)
+
+const (
+	// SqlTablePartition 查看数据表分区SQL，未分区的表PARTITION_NAME为NULL
+	SqlTablePartition = "SELECT `TABLE_NAME`,`PARTITION_NAME`,`SUBPARTITION_NAME`,`PARTITION_METHOD`,`PARTITION_EXPRESSION`,`SUBPARTITION_METHOD`,`SUBPARTITION_EXPRESSION`,`PARTITION_DESCRIPTION`,`TABLE_ROWS`,`PARTITION_COMMENT` FROM `information_schema`.`PARTITIONS` WHERE `TABLE_SCHEMA`='%s' AND `PARTITION_NAME` IS NOT NULL%s ORDER BY `TABLE_NAME`,`PARTITION_ORDINAL_POSITION`,`SUBPARTITION_ORDINAL_POSITION`"
+)
+
+// extendPartition 补充表的分区方式及各分区
+func (m *Mariadb) extendPartition(table *common.Table) error {
+	if m.partitions != nil {
+		table.Partitioning = m.partitions[table.Name]
+		return nil
+	}
+	partitions, err := m.queryPartition(" AND `TABLE_NAME`=" + common.QuoteList([]string{table.Name}))
+	if err != nil {
+		m.Conf.Logf("execute query table partition error, detail is [%v]\n", err.Error())
+		return err
+	}
+	table.Partitioning = partitions[table.Name]
+	return nil
+}
+
+// queryPartition 查询分区，filter为附加的过滤条件
+func (m *Mariadb) queryPartition(filter string) (map[string]*common.Partitioning, error) {
+	rows, err := m.DB.Raw(fmt.Sprintf(SqlTablePartition, m.Conf.Database, filter)).Rows()
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	partitions := make(map[string]*common.Partitioning)
+	for rows.Next() {
+		var tableName string
+		var name, subName, method, expression, subMethod, subExpression, description, comment sql.NullString
+		var tableRows sql.NullInt64
+		err = rows.Scan(&tableName, &name, &subName, &method, &expression, &subMethod, &subExpression, &description, &tableRows, &comment)
+		if err != nil {
+			return nil, err
+		}
+		partitioning, ok := partitions[tableName]
+		if !ok {
+			partitioning = &common.Partitioning{
+				Method:        method.String,
+				Expression:    expression.String,
+				SubMethod:     subMethod.String,
+				SubExpression: subExpression.String,
+			}
+			partitions[tableName] = partitioning
+		}
+		partitioning.Partitions = append(partitioning.Partitions, common.Partition{
+			Name:        name.String,
+			SubName:     subName.String,
+			Description: description.String,
+			Rows:        uint64(tableRows.Int64),
+			Comment:     comment.String,
+		})
+	}
+	return partitions, rows.Err()
+}
@@ -0,0 +1,150 @@
+package mariadb
+
+import (
+	"database/sql"
+	"fmt"
+	"mysql_to_md/common"
+	"strings"
+)
+
+const (
+	// SqlRoutines 查看存储过程及函数SQL
+	SqlRoutines = "SELECT `ROUTINE_NAME`,`ROUTINE_TYPE`,`DTD_IDENTIFIER`,`ROUTINE_COMMENT`,`ROUTINE_DEFINITION` FROM `information_schema`.`ROUTINES` WHERE `ROUTINE_SCHEMA`='%s' ORDER BY `ROUTINE_TYPE` DESC,`ROUTINE_NAME`"
+	// SqlRoutineParameters 查看存储过程及函数参数SQL，ORDINAL_POSITION为0的是函数返回值
+	SqlRoutineParameters = "SELECT `SPECIFIC_NAME`,`ROUTINE_TYPE`,`PARAMETER_MODE`,`PARAMETER_NAME`,`DTD_IDENTIFIER` FROM `information_schema`.`PARAMETERS` WHERE `SPECIFIC_SCHEMA`='%s' AND `ORDINAL_POSITION`>0 ORDER BY `SPECIFIC_NAME`,`ORDINAL_POSITION`"
+	// SqlTriggers 查看触发器SQL
+	SqlTriggers = "SELECT `TRIGGER_NAME`,`EVENT_MANIPULATION`,`EVENT_OBJECT_TABLE`,`ACTION_TIMING`,`ACTION_STATEMENT` FROM `information_schema`.`TRIGGERS` WHERE `TRIGGER_SCHEMA`='%s' ORDER BY `EVENT_OBJECT_TABLE`,`ACTION_TIMING`,`EVENT_MANIPULATION`,`ACTION_ORDER`"
+	// SqlEvents 查看定时事件SQL
+	SqlEvents = "SELECT `EVENT_NAME`,`EVENT_TYPE`,`EXECUTE_AT`,`INTERVAL_VALUE`,`INTERVAL_FIELD`,`STARTS`,`ENDS`,`STATUS`,`EVENT_COMMENT`,`EVENT_DEFINITION` FROM `information_schema`.`EVENTS` WHERE `EVENT_SCHEMA`='%s' ORDER BY `EVENT_NAME`"
+)
+
+// extendRoutines 补充存储过程、函数、触发器及定时事件，查询失败时忽略对应的部分
+func (m *Mariadb) extendRoutines(schema *common.Schema) {
+	routines, err := m.queryRoutines()
+	if err != nil {
+		m.Conf.Logf("execute query routines error,had ignored, detail is [%v]\n", err.Error())
+	}
+	schema.Routines = routines
+
+	triggers, err := m.queryTriggers()
+	if err != nil {
+		m.Conf.Logf("execute query triggers error,had ignored, detail is [%v]\n", err.Error())
+	}
+	schema.Triggers = triggers
+	m.triggers = make(map[string][]common.Trigger)
+	for _, trigger := range triggers {
+		m.triggers[trigger.Table] = append(m.triggers[trigger.Table], trigger)
+	}
+
+	events, err := m.queryEvents()
+	if err != nil {
+		m.Conf.Logf("execute query events error,had ignored, detail is [%v]\n", err.Error())
+	}
+	schema.Events = events
+}
+
+// queryRoutines 查询存储过程及函数和它们的参数
+func (m *Mariadb) queryRoutines() ([]common.Routine, error) {
+	rows, err := m.DB.Raw(fmt.Sprintf(SqlRoutines, m.Conf.Database)).Rows()
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var routines []common.Routine
+	for rows.Next() {
+		var routine common.Routine
+		err = rows.Scan(&routine.Name, &routine.Type, &routine.Returns, &routine.Comment, &routine.Body)
+		if err != nil {
+			return nil, err
+		}
+		routines = append(routines, routine)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	parameters, err := m.queryRoutineParameters()
+	if err != nil {
+		return nil, err
+	}
+	for i := range routines {
+		routines[i].Parameters = parameters[routines[i].Type+" "+routines[i].Name]
+	}
+	return routines, nil
+}
+
+// queryRoutineParameters 查询参数，key为 "类型 名称"
+func (m *Mariadb) queryRoutineParameters() (map[string][]common.RoutineParameter, error) {
+	rows, err := m.DB.Raw(fmt.Sprintf(SqlRoutineParameters, m.Conf.Database)).Rows()
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	parameters := make(map[string][]common.RoutineParameter)
+	for rows.Next() {
+		var name, routineType string
+		var mode, parameterName sql.NullString
+		var parameter common.RoutineParameter
+		if err = rows.Scan(&name, &routineType, &mode, &parameterName, &parameter.Type); err != nil {
+			return nil, err
+		}
+		parameter.Mode = mode.String
+		parameter.Name = parameterName.String
+		parameters[routineType+" "+name] = append(parameters[routineType+" "+name], parameter)
+	}
+	return parameters, rows.Err()
+}
+
+// queryTriggers 查询触发器
+func (m *Mariadb) queryTriggers() ([]common.Trigger, error) {
+	rows, err := m.DB.Raw(fmt.Sprintf(SqlTriggers, m.Conf.Database)).Rows()
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var triggers []common.Trigger
+	for rows.Next() {
+		var trigger common.Trigger
+		err = rows.Scan(&trigger.Name, &trigger.Event, &trigger.Table, &trigger.Timing, &trigger.Statement)
+		if err != nil {
+			return nil, err
+		}
+		triggers = append(triggers, trigger)
+	}
+	return triggers, rows.Err()
+}
+
+// queryEvents 查询定时事件
+func (m *Mariadb) queryEvents() ([]common.Event, error) {
+	rows, err := m.DB.Raw(fmt.Sprintf(SqlEvents, m.Conf.Database)).Rows()
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var events []common.Event
+	for rows.Next() {
+		var event common.Event
+		var eventType string
+		var executeAt, intervalValue, intervalField, starts, ends, comment sql.NullString
+		err = rows.Scan(&event.Name, &eventType, &executeAt, &intervalValue, &intervalField, &starts, &ends, &event.Status, &comment, &event.Body)
+		if err != nil {
+			return nil, err
+		}
+		event.Comment = comment.String
+		// ONE TIME events run AT a time, RECURRING events run EVERY interval
+		if eventType == "ONE TIME" {
+			event.Schedule = "AT " + executeAt.String
+		} else {
+			schedule := []string{"EVERY", intervalValue.String, intervalField.String}
+			if starts.Valid {
+				schedule = append(schedule, "STARTS", starts.String)
+			}
+			if ends.Valid {
+				schedule = append(schedule, "ENDS", ends.String)
+			}
+			event.Schedule = strings.Join(schedule, " ")
+		}
+		events = append(events, event)
+	}
+	return events, rows.Err()
+}
@@ -0,0 +1,112 @@
+package mariadb
+
+import (
+	"fmt"
+	"mysql_to_md/common"
+	"regexp"
+	"strings"
+)
+
+const (
+	// SqlViewTableUsage 查看视图引用的表SQL，mysql 8.0.13 及以上版本支持
+	SqlViewTableUsage = "SELECT `VIEW_NAME`,`TABLE_SCHEMA`,`TABLE_NAME` FROM `information_schema`.`VIEW_TABLE_USAGE` WHERE `VIEW_SCHEMA`='%s' ORDER BY `VIEW_NAME`,`TABLE_NAME`"
+	// SqlViewDefinition 查看视图定义SQL
+	SqlViewDefinition = "SELECT `TABLE_NAME`,`VIEW_DEFINITION` FROM `information_schema`.`VIEWS` WHERE `TABLE_SCHEMA`='%s' ORDER BY `TABLE_NAME`"
+)
+
+// viewTableReg 视图定义中 FROM、JOIN 的表，如 `db`.`table`
+var viewTableReg = regexp.MustCompile("(?i)\\b(?:FROM|JOIN)\\s+((?:`[^`]+`|\\w+)(?:\\.(?:`[^`]+`|\\w+))?)")
+
+// queryCreateView 查询视图创建语句
+func (m *Mariadb) queryCreateView(viewName string) (string, error) {
+	var createSql viewCreateSql
+	rows, err := m.DB.Raw(fmt.Sprintf(SqlViewCreate, quoteName(viewName))).Rows()
+	if err != nil {
+		m.Conf.Logf("execute query view create sql error, detail is [%v]\n", err.Error())
+		return "", err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		err = rows.Scan(&createSql.View, &createSql.CreateSql, &createSql.CharacterSetClient, &createSql.CollationConnection)
+		if err != nil {
+			return "", err
+		}
+	}
+	return createSql.CreateSql, rows.Err()
+}
+
+// ExtendSchema 补充视图引用的表，作为表到视图的数据流向，以及存储过程、函数、触发器、定时事件
+func (m *Mariadb) ExtendSchema(schema *common.Schema) error {
+	m.extendRoutines(schema)
+
+	edges, err := m.queryViewTableUsage()
+	if err != nil {
+		// VIEW_TABLE_USAGE is missing before mysql 8.0.13 and in mariadb
+		edges, err = m.parseViewDefinition()
+		if err != nil {
+			m.Conf.Logf("execute query view definition error, detail is [%v]\n", err.Error())
+			return err
+		}
+	}
+	schema.Lineage = append(schema.Lineage, edges...)
+	return nil
+}
+
+// queryViewTableUsage 从 VIEW_TABLE_USAGE 查询视图引用的表
+func (m *Mariadb) queryViewTableUsage() ([]common.LineageEdge, error) {
+	rows, err := m.DB.Raw(fmt.Sprintf(SqlViewTableUsage, m.Conf.Database)).Rows()
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var edges []common.LineageEdge
+	for rows.Next() {
+		var viewName, tableSchema, tableName string
+		if err = rows.Scan(&viewName, &tableSchema, &tableName); err != nil {
+			return nil, err
+		}
+		edges = append(edges, common.LineageEdge{From: m.tableName(tableSchema, tableName), To: viewName})
+	}
+	return edges, rows.Err()
+}
+
+// parseViewDefinition 从视图定义中解析引用的表
+func (m *Mariadb) parseViewDefinition() ([]common.LineageEdge, error) {
+	rows, err := m.DB.Raw(fmt.Sprintf(SqlViewDefinition, m.Conf.Database)).Rows()
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var edges []common.LineageEdge
+	for rows.Next() {
+		var viewName, definition string
+		if err = rows.Scan(&viewName, &definition); err != nil {
+			return nil, err
+		}
+		seen := make(map[string]bool)
+		for _, match := range viewTableReg.FindAllStringSubmatch(definition, -1) {
+			name := strings.ReplaceAll(match[1], "`", "")
+			if i := strings.Index(name, "."); i >= 0 {
+				name = m.tableName(name[:i], name[i+1:])
+			}
+			if !seen[name] {
+				seen[name] = true
+				edges = append(edges, common.LineageEdge{From: name, To: viewName})
+			}
+		}
+	}
+	return edges, rows.Err()
+}
+
+// tableName 当前库的表只保留表名，其他库的表为 database.table
+func (m *Mariadb) tableName(database, table string) string {
+	if database == "" || database == m.Conf.Database {
+		return table
+	}
+	return database + "." + table
+}
+
+// quoteName 用反引号包围标识符，名称中的反引号写两次，保留字及含 '-' 的名称也可以查询
+func quoteName(name string) string {
+	return "`" + strings.ReplaceAll(name, "`", "``") + "`"
+}
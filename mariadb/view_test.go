@@ -0,0 +1,16 @@
+package mariadb
+
+import "testing"
+
+func TestQuoteName(t *testing.T) {
+	cases := map[string]string{
+		"order":      "`order`",
+		"user-stats": "`user-stats`",
+		"a`b":        "`a``b`",
+	}
+	for name, quoted := range cases {
+		if result := quoteName(name); result != quoted {
+			t.Errorf("quoteName(%q) = %q, want %q", name, result, quoted)
+		}
+	}
+}
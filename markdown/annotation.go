@@ -0,0 +1,35 @@
+package markdown
+
+import (
+	"mysql_to_md/common"
+	"strings"
+)
+
+// annotationContent 注释补充文件中表的说明、负责人、标签及废弃说明
+func annotationContent(info *common.TableInfo) string {
+	var content string
+	if info.CommentSource == common.SourceDoc {
+		content += "\n> 表注释来源：文档（注释补充文件）\n"
+	}
+	annotation := info.Annotation
+	if annotation == nil {
+		return content
+	}
+	if annotation.Deprecated != "" {
+		content += "\n> **已废弃**：" + annotation.Deprecated + "\n"
+	}
+	if annotation.Description != "" {
+		content += "\n> 说明：" + strings.ReplaceAll(strings.TrimSpace(annotation.Description), "\n", "\n> ") + "\n"
+	}
+	var items []string
+	if len(annotation.Owners) != 0 {
+		items = append(items, "负责人："+strings.Join(annotation.Owners, ", "))
+	}
+	if len(annotation.Tags) != 0 {
+		items = append(items, "标签："+strings.Join(annotation.Tags, ", "))
+	}
+	if len(items) != 0 {
+		content += "\n> " + strings.Join(items, "；") + "\n"
+	}
+	return content
+}
@@ -0,0 +1,37 @@
+package markdown
+
+import (
+	"fmt"
+	"mysql_to_md/common"
+	"strings"
+)
+
+// distributedContent 分布式表的集群、本地表、分片键及副本结构差异
+func distributedContent(distributed *common.DistributedTable) string {
+	content := "\n> 集群：" + distributed.Cluster + "，本地表：" + distributed.LocalTable
+	if distributed.ShardingKey != "" {
+		content += "，分片键：" + distributed.ShardingKey
+	}
+	content += "\n"
+	if len(distributed.Drift) != 0 {
+		content += "\n**副本结构不一致**\n\n"
+		for _, drift := range distributed.Drift {
+			content += "- " + drift + "\n"
+		}
+	}
+	return content
+}
+
+// clusterContent 集群拓扑
+func clusterContent(schema *common.Schema) string {
+	if len(schema.Clusters) == 0 {
+		return ""
+	}
+	content := "### 集群\n\n" +
+		"| 集群 | 分片数 | 副本数 | 节点 |\n" +
+		"| :--: | :--: | :--: | :--: |\n"
+	for _, cluster := range schema.Clusters {
+		content += fmt.Sprintf("| %s | %d | %d | %s |\n", cluster.Name, cluster.Shards, cluster.Replicas, strings.Join(cluster.Hosts, "<br>"))
+	}
+	return content + "\n"
+}
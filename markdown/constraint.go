@@ -0,0 +1,28 @@
+package markdown
+
+import (
+	"mysql_to_md/common"
+	"strings"
+)
+
+// constraintContent 表的主键、唯一、外键及CHECK约束，foreignKeys 为false时不输出外键
+func constraintContent(constraints []common.Constraint, foreignKeys bool) string {
+	var content string
+	for _, constraint := range constraints {
+		if !foreignKeys && constraint.Type == "FOREIGN KEY" {
+			continue
+		}
+		expression := constraint.Expression
+		if constraint.ReferencedTable != "" {
+			expression = constraint.ReferencedTable + "(" + strings.Join(constraint.ReferencedColumns, ", ") + ")"
+		}
+		content += "| " + constraint.Name + " | " + constraint.Type + " | " + escape(strings.Join(constraint.Columns, ", ")) +
+			" | " + escape(expression) + " | " + constraint.Enforced + " |\n"
+	}
+	if content == "" {
+		return ""
+	}
+	return "\n**约束**\n\n" +
+		"| 名称 | 类型 | 字段 | 表达式/引用 | 强制执行 |\n" +
+		"| :--: | :--: | :--: | :--: | :--: |\n" + content
+}
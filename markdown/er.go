@@ -0,0 +1,98 @@
+package markdown
+
+import (
+	"mysql_to_md/common"
+	"regexp"
+	"strings"
+)
+
+// entity ER图中的一个表
+type entity struct {
+	name        string
+	columns     []common.TableColumn
+	constraints []common.Constraint
+}
+
+// identifier mermaid erDiagram 中实体名、字段名及类型不能包含的字符
+var identifier = regexp.MustCompile(`[^A-Za-z0-9_-]`)
+
+// erContent 模块内各表的字段及外键关系，使用mermaid erDiagram，foreignKeys 为false时不输出关系
+func erContent(entities []entity, foreignKeys bool) string {
+	if len(entities) == 0 {
+		return ""
+	}
+	var content, relations string
+	for _, e := range entities {
+		keys := make(map[string][]string)
+		for _, constraint := range e.constraints {
+			key := ""
+			switch constraint.Type {
+			case "PRIMARY KEY":
+				key = "PK"
+			case "UNIQUE":
+				key = "UK"
+			case "FOREIGN KEY":
+				if !foreignKeys {
+					continue
+				}
+				key = "FK"
+				relations += "    " + erName(e.name) + " }o--|| " + erName(constraint.ReferencedTable) +
+					" : \"" + erLabel(strings.Join(constraint.Columns, ", ")) + "\"\n"
+			}
+			for _, column := range constraint.Columns {
+				if key != "" && !contains(keys[column], key) {
+					keys[column] = append(keys[column], key)
+				}
+			}
+		}
+		content += "    " + erName(e.name) + " {\n"
+		for _, column := range e.columns {
+			columnKeys := keys[column.ColumnName]
+			if len(e.constraints) == 0 && (column.ColumnKey.String == "PRI" || column.InPrimaryKey) {
+				columnKeys = []string{"PK"}
+			}
+			line := "        " + erType(column.ColumnType) + " " + erName(column.ColumnName)
+			if len(columnKeys) != 0 {
+				line += " " + strings.Join(columnKeys, ", ")
+			}
+			if column.ColumnComment.String != "" {
+				line += " \"" + erLabel(column.ColumnComment.String) + "\""
+			}
+			content += line + "\n"
+		}
+		content += "    }\n"
+	}
+	return "\n**ER图**\n\n" +
+		"```mermaid\n" +
+		"erDiagram\n" +
+		content +
+		relations +
+		"```\n\n"
+}
+
+// erName 实体名及字段名，不支持的字符替换为 '_'
+func erName(name string) string {
+	return identifier.ReplaceAllString(name, "_")
+}
+
+// erType 字段类型，去掉长度、精度等参数，如 varchar(32) 为 varchar
+func erType(columnType string) string {
+	if i := strings.IndexAny(columnType, "( "); i > 0 {
+		columnType = columnType[:i]
+	}
+	return erName(columnType)
+}
+
+// erLabel 引号中的说明，去掉引号及换行
+func erLabel(s string) string {
+	return strings.NewReplacer(`"`, "'", "\n", " ", "\r", "").Replace(s)
+}
+
+func contains(items []string, item string) bool {
+	for _, i := range items {
+		if i == item {
+			return true
+		}
+	}
+	return false
+}
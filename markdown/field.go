@@ -0,0 +1,127 @@
+package markdown
+
+import (
+	"fmt"
+	"mysql_to_md/common"
+	"strconv"
+	"strings"
+)
+
+// columnField 字段表格中的一列
+type columnField struct {
+	title string
+	value func(table *common.Table, column *common.TableColumn) string
+}
+
+// baseFields 字段表格固定输出的列
+var baseFields = []columnField{
+	{"序号", func(t *common.Table, c *common.TableColumn) string { return strconv.Itoa(int(c.OrdinalPosition)) }},
+	{"字段", func(t *common.Table, c *common.TableColumn) string { return c.ColumnName }},
+	{"类型", func(t *common.Table, c *common.TableColumn) string { return c.ColumnType }},
+	{"键", func(t *common.Table, c *common.TableColumn) string { return c.ColumnKey.String }},
+	{"允许空", func(t *common.Table, c *common.TableColumn) string { return c.IsNullable }},
+	{"默认值", func(t *common.Table, c *common.TableColumn) string { return c.ColumnDefault.String }},
+	{"注释", func(t *common.Table, c *common.TableColumn) string {
+		if c.CommentSource == common.SourceDoc {
+			return escape(c.ColumnComment.String) + "（文档）"
+		}
+		return escape(c.ColumnComment.String)
+	}},
+}
+
+// optionalFields 可选列，表中任一字段有值时才输出
+var optionalFields = []columnField{
+	{"额外属性", func(t *common.Table, c *common.TableColumn) string { return c.Extra.String }},
+	{"生成表达式", func(t *common.Table, c *common.TableColumn) string { return escape(c.GenerationExpression.String) }},
+	// charset and collation only show when different from the table default
+	{"字符集", func(t *common.Table, c *common.TableColumn) string {
+		if t.Collation == "" || c.CharacterSet.String == t.Charset() {
+			return ""
+		}
+		return c.CharacterSet.String
+	}},
+	{"排序规则", func(t *common.Table, c *common.TableColumn) string {
+		if t.Collation == "" || c.Collation.String == t.Collation {
+			return ""
+		}
+		return c.Collation.String
+	}},
+	{"SRS ID", func(t *common.Table, c *common.TableColumn) string {
+		if !c.SrsID.Valid {
+			return ""
+		}
+		return strconv.FormatInt(c.SrsID.Int64, 10)
+	}},
+	// annotations from the annotations file
+	{"说明", func(t *common.Table, c *common.TableColumn) string { return escape(annotationOf(c).Description) }},
+	{"示例", func(t *common.Table, c *common.TableColumn) string { return escape(annotationOf(c).Example) }},
+	{"标签", func(t *common.Table, c *common.TableColumn) string {
+		return escape(strings.Join(annotationOf(c).Tags, ", "))
+	}},
+	{"废弃", func(t *common.Table, c *common.TableColumn) string { return escape(annotationOf(c).Deprecated) }},
+	{"压缩编码", func(t *common.Table, c *common.TableColumn) string { return escape(c.Codec) }},
+	{"TTL", func(t *common.Table, c *common.TableColumn) string { return escape(c.TTL) }},
+}
+
+// statsFields 数据量统计列，开启统计且表中任一字段有值时才输出
+var statsFields = []columnField{
+	{"压缩后", func(t *common.Table, c *common.TableColumn) string { return formatBytes(c.CompressedBytes) }},
+	{"压缩前", func(t *common.Table, c *common.TableColumn) string { return formatBytes(c.UncompressedBytes) }},
+	{"压缩比", func(t *common.Table, c *common.TableColumn) string {
+		if c.CompressedBytes == 0 {
+			return ""
+		}
+		return fmt.Sprintf("%.2f", float64(c.UncompressedBytes)/float64(c.CompressedBytes))
+	}},
+}
+
+// columnFields 表需要输出的列
+func (m *Markdown) columnFields(table *common.Table) []columnField {
+	fields := append([]columnField{}, baseFields...)
+	candidates := optionalFields
+	if m.Conf.Stats {
+		candidates = append(append([]columnField{}, optionalFields...), statsFields...)
+	}
+	for _, field := range candidates {
+		for i := range table.Columns {
+			if field.value(table, &table.Columns[i]) != "" {
+				fields = append(fields, field)
+				break
+			}
+		}
+	}
+	return fields
+}
+
+// formatBytes 字节数转换为可读的大小，0时为空
+func formatBytes(bytes uint64) string {
+	if bytes == 0 {
+		return ""
+	}
+	const unit = 1024
+	if bytes < unit {
+		return fmt.Sprintf("%d B", bytes)
+	}
+	div, exp := uint64(unit), 0
+	for n := bytes / unit; n >= unit; n /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f %ciB", float64(bytes)/float64(div), "KMGTPE"[exp])
+}
+
+// sizeOrZero 与 formatBytes 相同，0时为 0 B
+func sizeOrZero(bytes uint64) string {
+	if bytes == 0 {
+		return "0 B"
+	}
+	return formatBytes(bytes)
+}
+
+// annotationOf 字段在注释补充文件中的信息，没有时为空
+func annotationOf(column *common.TableColumn) *common.ColumnAnnotation {
+	if column.Annotation == nil {
+		return &common.ColumnAnnotation{}
+	}
+	return column.Annotation
+}
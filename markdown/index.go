@@ -0,0 +1,55 @@
+package markdown
+
+import (
+	"fmt"
+	"mysql_to_md/common"
+	"strings"
+)
+
+// indexContent 表的索引及投影
+func indexContent(table *common.Table) string {
+	var content string
+	if len(table.Indexes) != 0 {
+		content += "\n**索引**\n\n" +
+			"| 名称 | 类型 | 表达式 | 粒度 |\n" +
+			"| :--: | :--: | :--: | :--: |\n"
+		for _, index := range table.Indexes {
+			content += fmt.Sprintf("| %s | %s | %s | %d |\n", index.Name, escape(index.Type), escape(index.Expression), index.Granularity)
+		}
+	}
+	if len(table.Projections) != 0 {
+		content += "\n**投影**\n\n" +
+			"| 名称 | 查询 |\n" +
+			"| :--: | :--: |\n"
+		for _, projection := range table.Projections {
+			content += "| " + projection.Name + " | " + escape(projection.Query) + " |\n"
+		}
+	}
+	return content
+}
+
+// dictionaryContent 字典的数据源、布局、生命周期、主键及属性
+func dictionaryContent(dictionary *common.Dictionary) string {
+	if dictionary == nil {
+		return ""
+	}
+	var keys, attributes []string
+	for i, name := range dictionary.Keys {
+		keys = append(keys, name+" "+dictionary.KeyTypes[i])
+	}
+	for i, name := range dictionary.AttributeNames {
+		attributes = append(attributes, name+" "+dictionary.AttributeTypes[i])
+	}
+	lifetime := fmt.Sprintf("%d", dictionary.LifetimeMin)
+	if dictionary.LifetimeMax != dictionary.LifetimeMin {
+		lifetime += " ~ " + fmt.Sprintf("%d", dictionary.LifetimeMax)
+	}
+	return "\n**字典配置**\n\n" +
+		"| 项目 | 值 |\n" +
+		"| :--: | :--: |\n" +
+		"| 数据源 | " + escape(dictionary.Source) + " |\n" +
+		"| 布局 | " + escape(dictionary.Layout) + " |\n" +
+		"| 生命周期(秒) | " + lifetime + " |\n" +
+		"| 主键 | " + escape(strings.Join(keys, ", ")) + " |\n" +
+		"| 属性 | " + escape(strings.Join(attributes, ", ")) + " |\n"
+}
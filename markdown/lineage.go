@@ -0,0 +1,64 @@
+package markdown
+
+import (
+	"fmt"
+	"mysql_to_md/common"
+	"strings"
+)
+
+// lineageContent 整个数据库的数据流向图，使用mermaid flowchart
+func lineageContent(schema *common.Schema) string {
+	if len(schema.Lineage) == 0 {
+		return ""
+	}
+	kinds := make(map[string]common.TableKind)
+	for _, table := range schema.Tables {
+		kinds[table.Name] = table.Kind
+	}
+
+	ids := make(map[string]string)
+	var nodes, edges string
+	node := func(name string) string {
+		if id, ok := ids[name]; ok {
+			return id
+		}
+		id := fmt.Sprintf("n%d", len(ids))
+		ids[name] = id
+		nodes += "    " + id + nodeShape(kinds[name], name) + "\n"
+		return id
+	}
+	for _, edge := range schema.Lineage {
+		from, to := node(edge.From), node(edge.To)
+		edges += "    " + from + " --> " + to + "\n"
+	}
+	return "### 数据流向\n\n" +
+		"```mermaid\n" +
+		"flowchart LR\n" +
+		nodes +
+		edges +
+		"```\n\n"
+}
+
+// nodeShape 按对象类型区分节点形状
+func nodeShape(kind common.TableKind, name string) string {
+	label := `"` + strings.ReplaceAll(name, `"`, "#quot;") + `"`
+	switch kind {
+	case common.KindKafka:
+		return "[/" + label + "/]"
+	case common.KindMaterializedView, common.KindView:
+		return "{{" + label + "}}"
+	case common.KindDistributed:
+		return "[[" + label + "]]"
+	case common.KindDictionary:
+		return "[(" + label + ")]"
+	}
+	return "[" + label + "]"
+}
+
+// joinOrNone 以逗号连接，为空时输出 '-'
+func joinOrNone(names []string) string {
+	if len(names) == 0 {
+		return "-"
+	}
+	return strings.Join(names, ", ")
+}
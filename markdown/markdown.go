@@ -0,0 +1,238 @@
+package markdown
+
+import (
+	"fmt"
+	"io"
+	"mysql_to_md/common"
+	"strconv"
+	"strings"
+)
+
+func init() {
+	common.RegisterRenderer("markdown", New)
+}
+
+// Markdown markdown格式文档
+type Markdown struct {
+	Conf *common.Conf
+
+	// grouped 文档中有多种类型的对象或按模块分组时才输出分组标题
+	grouped bool
+	// number 表的序号，按模块分组时每个模块重新编号
+	number int
+	// module 当前分组为模块，entities 当前模块中的表，模块结束时输出ER图
+	module   bool
+	entities []entity
+	// notes 已有文档中的手写备注，noted 已输出备注区域的表
+	notes map[string]string
+	noted map[string]bool
+	// anchors 各表的锚点，files 分文件输出时各表的文件名，key为表名
+	anchors map[string]string
+	files   map[string]string
+	// foreignKeys 数据库支持外键，不支持时不输出外键约束及ER图中的关系
+	foreignKeys bool
+}
+
+// New 创建markdown渲染器
+func New(conf *common.Conf) common.Renderer {
+	return &Markdown{Conf: conf}
+}
+
+// Begin 文档标题及目录
+func (m *Markdown) Begin(w io.Writer, schema *common.Schema) error {
+	m.grouped = len(schema.Groups) > 1 || (len(schema.Groups) == 1 && schema.Groups[0].Kind == "")
+	m.notes, m.noted = notesOf(schema), make(map[string]bool)
+	m.foreignKeys = schema.Capabilities.ForeignKeys
+	m.anchors = anchors(schema.Tables)
+	if m.Conf.Split {
+		m.files = common.TableFileNames(schema.Tables)
+	}
+	_, err := io.WriteString(w, "## "+schema.Database+" tables message\n"+m.tocContent(schema))
+	return err
+}
+
+// Group 对象类型或模块的分组标题，模块还输出说明，并重新编号
+func (m *Markdown) Group(w io.Writer, group *common.TableGroup) error {
+	m.module = group.Kind == ""
+	if !m.grouped {
+		return nil
+	}
+	content := "### " + group.Title + "\n"
+	if group.Kind == "" {
+		m.number = 0
+		m.entities = nil
+		if group.Description != "" {
+			content += "\n" + group.Description + "\n\n"
+		}
+	}
+	_, err := io.WriteString(w, content)
+	return err
+}
+
+// GroupEnd 模块的ER图
+func (m *Markdown) GroupEnd(w io.Writer, group *common.TableGroup) error {
+	if !m.module {
+		return nil
+	}
+	_, err := io.WriteString(w, erContent(m.entities, m.foreignKeys))
+	m.entities = nil
+	return err
+}
+
+// Table 单个表的字段及建表语句
+func (m *Markdown) Table(w io.Writer, index int, table *common.Table) error {
+	m.number++
+	if m.module {
+		m.entities = append(m.entities, entity{name: table.Name, columns: table.Columns, constraints: table.Constraints})
+	}
+	// markdown header title, with an explicit anchor derived from the table name
+	tableContent := "#### <a id=\"" + m.anchorOf(table.Name) + "\"></a>" + strconv.Itoa(m.number) + "、 " + tableTitle(&table.TableInfo) + "\n"
+	if m.Conf.Split {
+		// without number, so that adding a table does not change documents of other tables
+		tableContent = "# <a id=\"" + m.anchorOf(table.Name) + "\"></a>" + tableTitle(&table.TableInfo) + "\n"
+	}
+
+	tableContent += m.infoContent(&table.TableInfo)
+	tableContent += annotationContent(&table.TableInfo)
+	tableContent += shardContent(table.Shards)
+
+	if table.Stats != nil {
+		tableContent += fmt.Sprintf("\n> 行数：%d，大小：%s，分区片段：%d\n", table.Stats.Rows, formatBytes(table.Stats.Bytes), table.Stats.Parts)
+	}
+
+	if table.Distributed != nil {
+		tableContent += distributedContent(table.Distributed)
+	}
+	if len(table.FedBy) != 0 || len(table.FeedsInto) != 0 {
+		source := "数据来源"
+		if table.Kind == common.KindView {
+			source = "引用的表"
+		}
+		tableContent += "\n> " + source + "：" + joinOrNone(table.FedBy) + "；数据流向：" + joinOrNone(table.FeedsInto) + "\n"
+	}
+
+	tableContent += m.noteContent(table.Name)
+
+	// markdown table header, optional fields only show when any column has value
+	fields := m.columnFields(table)
+	var titles, aligns []string
+	for _, field := range fields {
+		titles = append(titles, field.title)
+		aligns = append(aligns, ":--:")
+	}
+	tableContent += "\n" +
+		"| " + strings.Join(titles, " | ") + " |\n" +
+		"| " + strings.Join(aligns, " | ") + " |\n"
+	for i := range table.Columns {
+		var values []string
+		for _, field := range fields {
+			values = append(values, field.value(table, &table.Columns[i]))
+		}
+		tableContent += "| " + strings.Join(values, " | ") + " |\n"
+	}
+	tableContent += valueContent(table.Columns)
+	tableContent += storageContent(table)
+	tableContent += indexContent(table)
+	tableContent += constraintContent(table.Constraints, m.foreignKeys)
+	tableContent += partitionContent(table.Partitioning)
+	tableContent += dictionaryContent(table.Dictionary)
+	tableContent += triggerContent(table.Triggers)
+	tableContent += "\n\n```sql\n"
+	tableContent += table.CreateSql
+	tableContent += "\n```\n\n"
+	_, err := io.WriteString(w, tableContent)
+	return err
+}
+
+// End 文档结尾，存储过程、函数、触发器、定时事件、集群拓扑、数据流向图及已删除表的备注
+func (m *Markdown) End(w io.Writer, schema *common.Schema) error {
+	_, err := io.WriteString(w, routineContent(schema)+eventContent(schema)+clusterContent(schema)+lineageContent(schema)+m.orphanNoteContent())
+	return err
+}
+
+// storageContent 表的存储结构，包括引擎参数、排序键、分区键、主键、采样键、TTL及对应的字段
+func storageContent(table *common.Table) string {
+	if table.Storage == nil {
+		return ""
+	}
+	var sortingColumns, primaryColumns []string
+	for _, column := range table.Columns {
+		if column.InSortingKey {
+			sortingColumns = append(sortingColumns, column.ColumnName)
+		}
+		if column.InPrimaryKey {
+			primaryColumns = append(primaryColumns, column.ColumnName)
+		}
+	}
+	items := [][2]string{
+		{"引擎", table.Storage.EngineFull},
+		{"排序键 ORDER BY", table.Storage.SortingKey},
+		{"分区键 PARTITION BY", table.Storage.PartitionKey},
+		{"主键 PRIMARY KEY", table.Storage.PrimaryKey},
+		{"采样键 SAMPLE BY", table.Storage.SamplingKey},
+		{"TTL", table.Storage.TTL},
+		{"排序键字段", strings.Join(sortingColumns, ", ")},
+		{"主键字段", strings.Join(primaryColumns, ", ")},
+	}
+
+	content := "\n**存储结构**\n\n" +
+		"| 项目 | 值 |\n" +
+		"| :--: | :--: |\n"
+	for _, item := range items {
+		if item[1] == "" {
+			continue
+		}
+		content += "| " + item[0] + " | " + escape(item[1]) + " |\n"
+	}
+	return content
+}
+
+// escape 转义表格单元格中的 '|' 并去掉换行
+func escape(s string) string {
+	return strings.ReplaceAll(strings.ReplaceAll(s, "|", "\\|"), "\n", "")
+}
+
+// infoContent 标题下的表属性，引擎、字符集、排序规则、行格式，开启统计时还包括行数、大小、自增值及时间
+func (m *Markdown) infoContent(info *common.TableInfo) string {
+	var items []string
+	if info.Engine != "" {
+		items = append(items, "引擎："+info.Engine)
+	}
+	if info.Collation != "" {
+		items = append(items, "字符集："+info.Charset(), "排序规则："+info.Collation)
+	}
+	if info.RowFormat != "" {
+		items = append(items, "行格式："+info.RowFormat)
+	}
+	var content string
+	if len(items) != 0 {
+		content += "\n> " + strings.Join(items, "，") + "\n"
+	}
+	if !m.Conf.Stats {
+		return content
+	}
+
+	items = nil
+	if info.Rows.Valid {
+		items = append(items, fmt.Sprintf("预估行数：%d", info.Rows.Int64))
+	}
+	if info.DataLength.Valid {
+		items = append(items, "数据大小："+sizeOrZero(uint64(info.DataLength.Int64)))
+	}
+	if info.IndexLength.Valid {
+		items = append(items, "索引大小："+sizeOrZero(uint64(info.IndexLength.Int64)))
+	}
+	if info.AutoIncrement.Valid {
+		items = append(items, fmt.Sprintf("自增值：%d", info.AutoIncrement.Int64))
+	}
+	if info.CreateTime.Valid {
+		items = append(items, "创建时间："+info.CreateTime.String)
+	}
+	if info.UpdateTime.Valid {
+		items = append(items, "更新时间："+info.UpdateTime.String)
+	}
+	if len(items) != 0 {
+		content += ">\n> " + strings.Join(items, "，") + "\n"
+	}
+	return content
+}
@@ -0,0 +1,99 @@
+package markdown
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"mysql_to_md/common"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+const (
+	noteBegin = "<!-- user-content-begin: %s -->"
+	noteEnd   = "<!-- user-content-end: %s -->"
+)
+
+// noteBeginPattern 手写备注区域的开始标记，分组为表名
+var noteBeginPattern = regexp.MustCompile(`^<!-- user-content-begin: (.+?) -->$`)
+
+// ParseNotes 读取已有文档中标记的手写备注区域，同一个表有多个区域时按顺序拼接。
+// 区域缺少结束标记时返回错误，避免重新生成时丢失其中的备注
+func (m *Markdown) ParseNotes(r io.Reader, notes map[string]string) error {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), 16*1024*1024)
+	var table string
+	var lines []string
+	var lineNo, beginNo int
+	inNote := false
+	for scanner.Scan() {
+		lineNo++
+		line := strings.TrimRight(scanner.Text(), "\r")
+		match := noteBeginPattern.FindStringSubmatch(line)
+		if !inNote {
+			if match != nil {
+				table, lines, beginNo, inNote = match[1], nil, lineNo, true
+			}
+			continue
+		}
+		if match != nil {
+			return fmt.Errorf("user content of table %s at line %d is not terminated by %q", table, beginNo, fmt.Sprintf(noteEnd, table))
+		}
+		if line != fmt.Sprintf(noteEnd, table) {
+			lines = append(lines, line)
+			continue
+		}
+		inNote = false
+		if note := strings.Join(lines, "\n"); strings.TrimSpace(note) != "" {
+			if notes[table] != "" {
+				note = notes[table] + "\n" + note
+			}
+			notes[table] = note
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return err
+	}
+	if inNote {
+		return fmt.Errorf("user content of table %s at line %d is not terminated by %q", table, beginNo, fmt.Sprintf(noteEnd, table))
+	}
+	return nil
+}
+
+// noteContent 表的手写备注区域，区域内的内容在重新生成时保留
+func (m *Markdown) noteContent(name string) string {
+	note := m.notes[name]
+	m.noted[name] = true
+	if note != "" {
+		note += "\n"
+	}
+	return "\n" + fmt.Sprintf(noteBegin, name) + "\n" + note + fmt.Sprintf(noteEnd, name) + "\n"
+}
+
+// orphanNoteContent 本次没有输出的表的手写备注，表已删除或被过滤，保留到表重新出现
+func (m *Markdown) orphanNoteContent() string {
+	var names []string
+	for name := range m.notes {
+		if !m.noted[name] {
+			names = append(names, name)
+		}
+	}
+	if len(names) == 0 {
+		return ""
+	}
+	sort.Strings(names)
+	content := "### 已删除表的备注\n"
+	for _, name := range names {
+		content += "\n#### " + name + "\n" + m.noteContent(name)
+	}
+	return content + "\n"
+}
+
+// notesOf schema中的手写备注
+func notesOf(schema *common.Schema) map[string]string {
+	if schema.Notes == nil {
+		return map[string]string{}
+	}
+	return schema.Notes
+}
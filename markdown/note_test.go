@@ -0,0 +1,65 @@
+package markdown
+
+import (
+	"mysql_to_md/common"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// render 输出带有手写备注的完整文档
+func render(t *testing.T, tables []common.TableInfo, notes map[string]string) string {
+	m := New(&common.Conf{}).(*Markdown)
+	schema := &common.Schema{
+		Database: "test",
+		Tables:   tables,
+		Groups:   []common.TableGroup{{Kind: common.KindTable, Title: "表", Tables: tables}},
+		Notes:    notes,
+	}
+	var w strings.Builder
+	if err := m.Begin(&w, schema); err != nil {
+		t.Fatal(err)
+	}
+	for i, info := range tables {
+		table := &common.Table{TableInfo: info, Columns: []common.TableColumn{{ColumnName: "id", ColumnType: "int"}}}
+		if err := m.Table(&w, i, table); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := m.End(&w, schema); err != nil {
+		t.Fatal(err)
+	}
+	return w.String()
+}
+
+func TestParseNotesRoundTrip(t *testing.T) {
+	notes := map[string]string{
+		"user":    "用户表由账号服务写入。\n\n- 不要直接修改 `status`",
+		"deleted": "已下线，数据保留一年",
+	}
+	document := render(t, []common.TableInfo{{Name: "user"}, {Name: "order"}}, notes)
+
+	parsed := make(map[string]string)
+	if err := New(&common.Conf{}).(*Markdown).ParseNotes(strings.NewReader(document), parsed); err != nil {
+		t.Fatalf("ParseNotes error: %v", err)
+	}
+	if !reflect.DeepEqual(parsed, notes) {
+		t.Errorf("ParseNotes = %q, want %q", parsed, notes)
+	}
+}
+
+func TestParseNotesUnterminated(t *testing.T) {
+	cases := map[string]string{
+		"end of document": "<!-- user-content-begin: user -->\nnote\n",
+		"next region":     "<!-- user-content-begin: user -->\nnote\n<!-- user-content-begin: order -->\n<!-- user-content-end: order -->\n",
+		"other table end": "<!-- user-content-begin: user -->\nnote\n<!-- user-content-end: order -->\n",
+	}
+	for name, document := range cases {
+		t.Run(name, func(t *testing.T) {
+			err := New(&common.Conf{}).(*Markdown).ParseNotes(strings.NewReader(document), map[string]string{})
+			if err == nil || !strings.Contains(err.Error(), "user") {
+				t.Errorf("ParseNotes error = %v, want unterminated error of table user", err)
+			}
+		})
+	}
+}
@@ -0,0 +1,33 @@
+package markdown
+
+import (
+	"fmt"
+	"mysql_to_md/common"
+)
+
+// partitionContent 表的分区方式、分区表达式及各分区的描述和预估行数
+func partitionContent(partitioning *common.Partitioning) string {
+	if partitioning == nil {
+		return ""
+	}
+	content := "\n**分区**\n\n> 分区方式：" + partitioning.Method + "(" + partitioning.Expression + ")"
+	if partitioning.SubMethod != "" {
+		content += "，子分区方式：" + partitioning.SubMethod + "(" + partitioning.SubExpression + ")"
+	}
+	content += "\n\n"
+	if partitioning.SubMethod != "" {
+		content += "| 分区 | 子分区 | 描述 | 预估行数 | 注释 |\n" +
+			"| :--: | :--: | :--: | :--: | :--: |\n"
+	} else {
+		content += "| 分区 | 描述 | 预估行数 | 注释 |\n" +
+			"| :--: | :--: | :--: | :--: |\n"
+	}
+	for _, partition := range partitioning.Partitions {
+		if partitioning.SubMethod != "" {
+			content += fmt.Sprintf("| %s | %s | %s | %d | %s |\n", partition.Name, partition.SubName, escape(partition.Description), partition.Rows, escape(partition.Comment))
+		} else {
+			content += fmt.Sprintf("| %s | %s | %d | %s |\n", partition.Name, escape(partition.Description), partition.Rows, escape(partition.Comment))
+		}
+	}
+	return content
+}
@@ -0,0 +1,74 @@
+package markdown
+
+import (
+	"mysql_to_md/common"
+	"strings"
+)
+
+// routineContent 存储过程、函数及触发器
+func routineContent(schema *common.Schema) string {
+	var content string
+	if len(schema.Routines) != 0 {
+		content += "### 存储过程和函数\n"
+		for _, routine := range schema.Routines {
+			var parameters []string
+			for _, parameter := range routine.Parameters {
+				parameters = append(parameters, strings.TrimSpace(parameter.Mode+" "+parameter.Name+" "+parameter.Type))
+			}
+			content += "#### " + routine.Name + "\n\n" +
+				"| 类型 | 参数 | 返回值 | 注释 |\n" +
+				"| :--: | :--: | :--: | :--: |\n" +
+				"| " + routine.Type + " | " + escape(strings.Join(parameters, ", ")) + " | " + routine.Returns.String + " | " + escape(routine.Comment) + " |\n"
+			if routine.Body.String != "" {
+				content += "\n```sql\n" + routine.Body.String + "\n```\n"
+			}
+			content += "\n"
+		}
+	}
+	if len(schema.Triggers) != 0 {
+		content += "### 触发器\n\n" +
+			"| 名称 | 表 | 时机 | 事件 |\n" +
+			"| :--: | :--: | :--: | :--: |\n"
+		for _, trigger := range schema.Triggers {
+			content += "| " + trigger.Name + " | " + trigger.Table + " | " + trigger.Timing + " | " + trigger.Event + " |\n"
+		}
+		content += "\n"
+	}
+	return content
+}
+
+// eventContent 定时事件
+func eventContent(schema *common.Schema) string {
+	if len(schema.Events) == 0 {
+		return ""
+	}
+	content := "### 定时事件\n"
+	for _, event := range schema.Events {
+		content += "#### " + event.Name + "\n\n" +
+			"| 调度 | 状态 | 注释 |\n" +
+			"| :--: | :--: | :--: |\n" +
+			"| " + escape(event.Schedule) + " | " + event.Status + " | " + escape(event.Comment) + " |\n"
+		if event.Body.String != "" {
+			content += "\n```sql\n" + event.Body.String + "\n```\n"
+		}
+		content += "\n"
+	}
+	return content
+}
+
+// triggerContent 表上的触发器
+func triggerContent(triggers []common.Trigger) string {
+	if len(triggers) == 0 {
+		return ""
+	}
+	content := "\n**触发器**\n\n" +
+		"| 名称 | 时机 | 事件 |\n" +
+		"| :--: | :--: | :--: |\n"
+	for _, trigger := range triggers {
+		content += "| " + trigger.Name + " | " + trigger.Timing + " | " + trigger.Event + " |\n"
+	}
+	for _, trigger := range triggers {
+		content += "\n```sql\n-- " + trigger.Name + "\n" + trigger.Statement + "\n```\n"
+	}
+	return content
+}
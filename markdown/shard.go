@@ -0,0 +1,23 @@
+package markdown
+
+import (
+	"fmt"
+	"mysql_to_md/common"
+	"strings"
+)
+
+// shardContent 合并的分表数量及结构不同的表
+func shardContent(shards *common.ShardFamily) string {
+	if shards == nil {
+		return ""
+	}
+	content := fmt.Sprintf("\n> 分表：共%d个结构相同的表，以下为 %s 的结构\n", len(shards.Members), shards.Members[0])
+	if len(shards.Differs) != 0 {
+		var differs []string
+		for _, diff := range shards.Differs {
+			differs = append(differs, diff.Table+"（"+strings.Join(diff.Reasons, "；")+"）")
+		}
+		content += "\n> 结构不同的表：" + escape(strings.Join(differs, "，")) + "\n"
+	}
+	return content
+}
@@ -0,0 +1,82 @@
+package markdown
+
+import (
+	"mysql_to_md/common"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// anchorInvalid 锚点中不保留的字符，各语言的字母及数字都保留
+var anchorInvalid = regexp.MustCompile(`[^\p{L}\p{N}_-]+`)
+
+// anchor 由表名生成的锚点，不随表的序号及注释变化，外部页面可以链接到 文档#表名。
+// 表名中有字符被替换时追加表名的哈希，a.b 与 a$b 不会得到相同的锚点
+func anchor(name string) string {
+	result := anchorInvalid.ReplaceAllString(name, "-")
+	if result != name {
+		result += "-" + common.NameHash(name)
+	}
+	return result
+}
+
+// anchors 文档中各表的锚点，key为表名，仍然重复的锚点按表的顺序追加 -2、-3 等后缀
+func anchors(tables []common.TableInfo) map[string]string {
+	result := make(map[string]string, len(tables))
+	used := make(map[string]bool, len(tables))
+	for _, table := range tables {
+		base := anchor(table.Name)
+		id := base
+		for n := 2; used[id]; n++ {
+			id = base + "-" + strconv.Itoa(n)
+		}
+		used[id] = true
+		result[table.Name] = id
+	}
+	return result
+}
+
+// anchorOf 表在文档中的锚点
+func (m *Markdown) anchorOf(name string) string {
+	if id, ok := m.anchors[name]; ok {
+		return id
+	}
+	return anchor(name)
+}
+
+// tableTitle 表标题中序号之后的部分，表名及注释
+func tableTitle(info *common.TableInfo) string {
+	if info.Comment.String == "" {
+		return info.DisplayName()
+	}
+	return info.DisplayName() + "-" + info.Comment.String
+}
+
+// tocContent 目录，分组时按分组输出，分文件输出时链接到各表的文件
+func (m *Markdown) tocContent(schema *common.Schema) string {
+	if len(schema.Tables) == 0 {
+		return ""
+	}
+	content := "\n### 目录\n\n"
+	for _, group := range schema.Groups {
+		indent := ""
+		if m.grouped {
+			content += "- " + group.Title + "\n"
+			indent = "  "
+		}
+		for i := range group.Tables {
+			link := "#" + m.anchorOf(group.Tables[i].Name)
+			if m.Conf.Split {
+				link = common.TablesDir + "/" + url.PathEscape(m.files[group.Tables[i].Name])
+			}
+			content += indent + "- [" + tocEscape(tableTitle(&group.Tables[i])) + "](" + link + ")\n"
+		}
+	}
+	return content + "\n"
+}
+
+// tocEscape 转义链接文字中的方括号
+func tocEscape(s string) string {
+	return strings.NewReplacer("[", "\\[", "]", "\\]", "\n", " ").Replace(s)
+}
@@ -0,0 +1,36 @@
+package markdown
+
+import (
+	"mysql_to_md/common"
+	"strings"
+	"testing"
+)
+
+func TestAnchors(t *testing.T) {
+	tables := []common.TableInfo{
+		{Name: "order_item"},
+		{Name: "用户"},
+		{Name: "订单"},
+		{Name: "a.b"},
+		{Name: "a$b"},
+		{Name: "a-b-" + common.NameHash("a.b")},
+	}
+	result := anchors(tables)
+	if result["order_item"] != "order_item" || result["用户"] != "用户" || result["订单"] != "订单" {
+		t.Errorf("anchors of plain names changed: %v", result)
+	}
+	if !strings.HasPrefix(result["a.b"], "a-b-") || !strings.HasPrefix(result["a$b"], "a-b-") {
+		t.Errorf("anchors of replaced names = %q, %q", result["a.b"], result["a$b"])
+	}
+	used := make(map[string]string)
+	for _, table := range tables {
+		id := result[table.Name]
+		if other, ok := used[id]; ok {
+			t.Errorf("tables %s and %s have the same anchor %s", other, table.Name, id)
+		}
+		used[id] = table.Name
+	}
+	if again := anchors(tables); again["a$b"] != result["a$b"] || again["a-b-"+common.NameHash("a.b")] != result["a-b-"+common.NameHash("a.b")] {
+		t.Error("anchors are not deterministic")
+	}
+}
@@ -0,0 +1,23 @@
+package markdown
+
+import "mysql_to_md/common"
+
+// valueContent 字段的可选值及含义，每个有取值的字段一组
+func valueContent(columns []common.TableColumn) string {
+	var content string
+	for _, column := range columns {
+		for i, value := range column.Values {
+			name := ""
+			if i == 0 {
+				name = column.ColumnName
+			}
+			content += "| " + name + " | " + escape(value.Value) + " | " + escape(value.Label) + " |\n"
+		}
+	}
+	if content == "" {
+		return ""
+	}
+	return "\n**取值说明**\n\n" +
+		"| 字段 | 值 | 含义 |\n" +
+		"| :--: | :--: | :--: |\n" + content
+}
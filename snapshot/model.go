@@ -0,0 +1,342 @@
+package snapshot
+
+import (
+	"database/sql"
+	"mysql_to_md/common"
+)
+
+// table 单个表的快照，字段名固定，不随内部结构变化
+type table struct {
+	Name          string               `json:"name"`
+	Kind          common.TableKind     `json:"kind"`
+	Comment       string               `json:"comment,omitempty"`
+	CommentSource common.CommentSource `json:"comment_source,omitempty"`
+	Description   string               `json:"description,omitempty"`
+	Owners        []string             `json:"owners,omitempty"`
+	Tags          []string             `json:"tags,omitempty"`
+	Deprecated    string               `json:"deprecated,omitempty"`
+	Engine        string               `json:"engine,omitempty"`
+	Collation     string               `json:"collation,omitempty"`
+	RowFormat     string               `json:"row_format,omitempty"`
+	Shards        *shardFamily         `json:"shards,omitempty"`
+	Stats         *tableStats          `json:"stats,omitempty"`
+	Columns       []column             `json:"columns"`
+	Constraints   []constraint         `json:"constraints,omitempty"`
+	Indexes       []index              `json:"indexes,omitempty"`
+	Projections   []projection         `json:"projections,omitempty"`
+	Storage       *storage             `json:"storage,omitempty"`
+	Partitioning  *partitioning        `json:"partitioning,omitempty"`
+	Dictionary    *dictionary          `json:"dictionary,omitempty"`
+	Distributed   *distributedTable    `json:"distributed,omitempty"`
+	Triggers      []trigger            `json:"triggers,omitempty"`
+	FedBy         []string             `json:"fed_by,omitempty"`
+	FeedsInto     []string             `json:"feeds_into,omitempty"`
+	CreateSql     string               `json:"create_sql"`
+}
+
+// shardFamily 合并的分表，name 为其中第一个表
+type shardFamily struct {
+	Pattern string      `json:"pattern"`
+	Members []string    `json:"members"`
+	Differs []shardDiff `json:"differs,omitempty"`
+}
+
+type shardDiff struct {
+	Table   string   `json:"table"`
+	Reasons []string `json:"reasons"`
+}
+
+// tableStats 每次生成都会变化的统计，只在开启统计时输出
+type tableStats struct {
+	Rows          *int64  `json:"rows,omitempty"`
+	DataLength    *int64  `json:"data_length,omitempty"`
+	IndexLength   *int64  `json:"index_length,omitempty"`
+	AutoIncrement *int64  `json:"auto_increment,omitempty"`
+	CreateTime    *string `json:"create_time,omitempty"`
+	UpdateTime    *string `json:"update_time,omitempty"`
+	Bytes         uint64  `json:"bytes,omitempty"`
+	Parts         uint64  `json:"parts,omitempty"`
+}
+
+type column struct {
+	Position             uint16               `json:"position"`
+	Name                 string               `json:"name"`
+	Type                 string               `json:"type"`
+	Key                  string               `json:"key,omitempty"`
+	Nullable             string               `json:"nullable,omitempty"`
+	Default              *string              `json:"default"`
+	Comment              string               `json:"comment,omitempty"`
+	CommentSource        common.CommentSource `json:"comment_source,omitempty"`
+	Description          string               `json:"description,omitempty"`
+	Tags                 []string             `json:"tags,omitempty"`
+	Deprecated           string               `json:"deprecated,omitempty"`
+	Example              string               `json:"example,omitempty"`
+	Extra                string               `json:"extra,omitempty"`
+	CharacterSet         string               `json:"character_set,omitempty"`
+	Collation            string               `json:"collation,omitempty"`
+	GenerationExpression string               `json:"generation_expression,omitempty"`
+	SrsID                *int64               `json:"srs_id,omitempty"`
+	InSortingKey         bool                 `json:"in_sorting_key,omitempty"`
+	InPrimaryKey         bool                 `json:"in_primary_key,omitempty"`
+	Codec                string               `json:"codec,omitempty"`
+	TTL                  string               `json:"ttl,omitempty"`
+	CompressedBytes      uint64               `json:"compressed_bytes,omitempty"`
+	UncompressedBytes    uint64               `json:"uncompressed_bytes,omitempty"`
+	Values               []value              `json:"values,omitempty"`
+}
+
+// value 字段的可选值，含义为空表示只有类型定义中的值
+type value struct {
+	Value string `json:"value"`
+	Label string `json:"label,omitempty"`
+}
+
+type constraint struct {
+	Name              string   `json:"name"`
+	Type              string   `json:"type"`
+	Columns           []string `json:"columns,omitempty"`
+	Expression        string   `json:"expression,omitempty"`
+	Enforced          string   `json:"enforced,omitempty"`
+	ReferencedTable   string   `json:"referenced_table,omitempty"`
+	ReferencedColumns []string `json:"referenced_columns,omitempty"`
+}
+
+type index struct {
+	Name        string `json:"name"`
+	Type        string `json:"type"`
+	Expression  string `json:"expression"`
+	Granularity uint64 `json:"granularity,omitempty"`
+}
+
+type projection struct {
+	Name  string `json:"name"`
+	Query string `json:"query"`
+}
+
+type storage struct {
+	EngineFull   string `json:"engine_full"`
+	SortingKey   string `json:"sorting_key,omitempty"`
+	PartitionKey string `json:"partition_key,omitempty"`
+	PrimaryKey   string `json:"primary_key,omitempty"`
+	SamplingKey  string `json:"sampling_key,omitempty"`
+	TTL          string `json:"ttl,omitempty"`
+}
+
+type partitioning struct {
+	Method        string      `json:"method"`
+	Expression    string      `json:"expression,omitempty"`
+	SubMethod     string      `json:"sub_method,omitempty"`
+	SubExpression string      `json:"sub_expression,omitempty"`
+	Partitions    []partition `json:"partitions"`
+}
+
+// partition 分区的行数是估算值，只在开启统计时输出
+type partition struct {
+	Name        string  `json:"name"`
+	SubName     string  `json:"sub_name,omitempty"`
+	Description string  `json:"description,omitempty"`
+	Rows        *uint64 `json:"rows,omitempty"`
+	Comment     string  `json:"comment,omitempty"`
+}
+
+type dictionary struct {
+	Source      string      `json:"source"`
+	Layout      string      `json:"layout"`
+	LifetimeMin uint64      `json:"lifetime_min"`
+	LifetimeMax uint64      `json:"lifetime_max"`
+	Keys        []attribute `json:"keys"`
+	Attributes  []attribute `json:"attributes"`
+}
+
+type attribute struct {
+	Name string `json:"name"`
+	Type string `json:"type"`
+}
+
+type distributedTable struct {
+	Cluster     string   `json:"cluster"`
+	LocalTable  string   `json:"local_table"`
+	ShardingKey string   `json:"sharding_key,omitempty"`
+	Drift       []string `json:"drift,omitempty"`
+}
+
+type trigger struct {
+	Name      string `json:"name"`
+	Event     string `json:"event"`
+	Table     string `json:"table"`
+	Timing    string `json:"timing"`
+	Statement string `json:"statement"`
+}
+
+type routine struct {
+	Name       string      `json:"name"`
+	Type       string      `json:"type"`
+	Returns    string      `json:"returns,omitempty"`
+	Comment    string      `json:"comment,omitempty"`
+	Parameters []parameter `json:"parameters,omitempty"`
+	Body       *string     `json:"body"`
+}
+
+type parameter struct {
+	Mode string `json:"mode,omitempty"`
+	Name string `json:"name"`
+	Type string `json:"type"`
+}
+
+type event struct {
+	Name     string  `json:"name"`
+	Schedule string  `json:"schedule"`
+	Status   string  `json:"status"`
+	Comment  string  `json:"comment,omitempty"`
+	Body     *string `json:"body"`
+}
+
+type lineageEdge struct {
+	From string `json:"from"`
+	To   string `json:"to"`
+}
+
+type cluster struct {
+	Name     string   `json:"name"`
+	Shards   int      `json:"shards"`
+	Replicas int      `json:"replicas"`
+	Hosts    []string `json:"hosts"`
+}
+
+// newTable 由文档内容生成表的快照，stats为false时不输出每次生成都会变化的统计
+func newTable(t *common.Table, stats bool) *table {
+	kind := t.Kind
+	if kind == "" {
+		kind = common.KindTable
+	}
+	s := &table{
+		Name:      t.Name,
+		Kind:      kind,
+		Comment:   t.Comment.String,
+		Engine:    t.Engine,
+		Collation: t.Collation,
+		RowFormat: t.RowFormat,
+		FedBy:     t.FedBy,
+		FeedsInto: t.FeedsInto,
+		CreateSql: t.CreateSql,
+		Columns:   []column{},
+	}
+	s.CommentSource = t.CommentSource
+	if a := t.Annotation; a != nil {
+		s.Description, s.Owners, s.Tags, s.Deprecated = a.Description, a.Owners, a.Tags, a.Deprecated
+	}
+	if t.Shards != nil {
+		s.Shards = &shardFamily{Pattern: t.Shards.Pattern, Members: t.Shards.Members}
+		for _, diff := range t.Shards.Differs {
+			s.Shards.Differs = append(s.Shards.Differs, shardDiff(diff))
+		}
+	}
+	if stats {
+		s.Stats = &tableStats{
+			Rows:          nullInt64(t.Rows),
+			DataLength:    nullInt64(t.DataLength),
+			IndexLength:   nullInt64(t.IndexLength),
+			AutoIncrement: nullInt64(t.AutoIncrement),
+			CreateTime:    nullString(t.CreateTime),
+			UpdateTime:    nullString(t.UpdateTime),
+		}
+		if t.Stats != nil {
+			rows := int64(t.Stats.Rows)
+			s.Stats.Rows = &rows
+			s.Stats.Bytes = t.Stats.Bytes
+			s.Stats.Parts = t.Stats.Parts
+		}
+	}
+	for _, c := range t.Columns {
+		col := column{
+			Position:             c.OrdinalPosition,
+			Name:                 c.ColumnName,
+			Type:                 c.ColumnType,
+			Key:                  c.ColumnKey.String,
+			Nullable:             c.IsNullable,
+			Default:              nullString(c.ColumnDefault),
+			Comment:              c.ColumnComment.String,
+			Extra:                c.Extra.String,
+			CharacterSet:         c.CharacterSet.String,
+			Collation:            c.Collation.String,
+			GenerationExpression: c.GenerationExpression.String,
+			SrsID:                nullInt64(c.SrsID),
+			InSortingKey:         c.InSortingKey,
+			InPrimaryKey:         c.InPrimaryKey,
+			Codec:                c.Codec,
+			TTL:                  c.TTL,
+		}
+		col.CommentSource = c.CommentSource
+		if a := c.Annotation; a != nil {
+			col.Description, col.Tags, col.Deprecated, col.Example = a.Description, a.Tags, a.Deprecated, a.Example
+		}
+		for _, v := range c.Values {
+			col.Values = append(col.Values, value(v))
+		}
+		if stats {
+			col.CompressedBytes = c.CompressedBytes
+			col.UncompressedBytes = c.UncompressedBytes
+		}
+		s.Columns = append(s.Columns, col)
+	}
+	for _, c := range t.Constraints {
+		s.Constraints = append(s.Constraints, constraint(c))
+	}
+	for _, i := range t.Indexes {
+		s.Indexes = append(s.Indexes, index(i))
+	}
+	for _, p := range t.Projections {
+		s.Projections = append(s.Projections, projection(p))
+	}
+	if t.Storage != nil {
+		st := storage(*t.Storage)
+		s.Storage = &st
+	}
+	if t.Partitioning != nil {
+		s.Partitioning = &partitioning{
+			Method:        t.Partitioning.Method,
+			Expression:    t.Partitioning.Expression,
+			SubMethod:     t.Partitioning.SubMethod,
+			SubExpression: t.Partitioning.SubExpression,
+		}
+		for _, p := range t.Partitioning.Partitions {
+			part := partition{Name: p.Name, SubName: p.SubName, Description: p.Description, Comment: p.Comment}
+			if stats {
+				rows := p.Rows
+				part.Rows = &rows
+			}
+			s.Partitioning.Partitions = append(s.Partitioning.Partitions, part)
+		}
+	}
+	if d := t.Dictionary; d != nil {
+		s.Dictionary = &dictionary{Source: d.Source, Layout: d.Layout, LifetimeMin: d.LifetimeMin, LifetimeMax: d.LifetimeMax}
+		for i, name := range d.Keys {
+			s.Dictionary.Keys = append(s.Dictionary.Keys, attribute{Name: name, Type: d.KeyTypes[i]})
+		}
+		for i, name := range d.AttributeNames {
+			s.Dictionary.Attributes = append(s.Dictionary.Attributes, attribute{Name: name, Type: d.AttributeTypes[i]})
+		}
+	}
+	if t.Distributed != nil {
+		d := distributedTable(*t.Distributed)
+		s.Distributed = &d
+	}
+	for _, tr := range t.Triggers {
+		s.Triggers = append(s.Triggers, trigger(tr))
+	}
+	return s
+}
+
+func nullString(s sql.NullString) *string {
+	if !s.Valid {
+		return nil
+	}
+	return &s.String
+}
+
+func nullInt64(i sql.NullInt64) *int64 {
+	if !i.Valid {
+		return nil
+	}
+	return &i.Int64
+}
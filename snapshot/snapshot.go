@@ -0,0 +1,124 @@
+// Package snapshot 以JSON输出表结构快照，供其他工具读取或比对不同时间的表结构
+package snapshot
+
+import (
+	"encoding/json"
+	"io"
+	"mysql_to_md/common"
+)
+
+func init() {
+	common.RegisterRenderer("json", New)
+}
+
+// Snapshot JSON格式的表结构快照，表按文档顺序流式写入 tables 数组
+type Snapshot struct {
+	Conf *common.Conf
+}
+
+// New 创建JSON快照Renderer
+func New(conf *common.Conf) common.Renderer {
+	return &Snapshot{Conf: conf}
+}
+
+// Extension 快照文件的扩展名
+func (s *Snapshot) Extension() string {
+	return ".json"
+}
+
+// Begin 快照开头及 tables 数组的开始
+func (s *Snapshot) Begin(w io.Writer, schema *common.Schema) error {
+	database, err := json.Marshal(schema.Database)
+	if err != nil {
+		return err
+	}
+	_, err = io.WriteString(w, "{\n  \"database\": "+string(database)+",\n  \"tables\": [")
+	return err
+}
+
+// Group 快照中的表带有类型，不输出分组
+func (s *Snapshot) Group(w io.Writer, group *common.TableGroup) error {
+	return nil
+}
+
+// Table 单个表的快照
+func (s *Snapshot) Table(w io.Writer, index int, t *common.Table) error {
+	content, err := json.MarshalIndent(newTable(t, s.Conf.Stats), "    ", "  ")
+	if err != nil {
+		return err
+	}
+	separator := "\n    "
+	if index > 0 {
+		separator = "," + separator
+	}
+	_, err = io.WriteString(w, separator+string(content))
+	return err
+}
+
+// End tables 数组的结尾，存储过程、函数、触发器、定时事件、数据流向及集群拓扑
+func (s *Snapshot) End(w io.Writer, schema *common.Schema) error {
+	content := "\n  ]"
+	sections := []struct {
+		name  string
+		value interface{}
+	}{
+		{"routines", routines(schema.Routines)},
+		{"triggers", triggers(schema.Triggers)},
+		{"events", events(schema.Events)},
+		{"lineage", lineage(schema.Lineage)},
+		{"clusters", clusters(schema.Clusters)},
+	}
+	for _, section := range sections {
+		value, err := json.MarshalIndent(section.value, "  ", "  ")
+		if err != nil {
+			return err
+		}
+		content += ",\n  \"" + section.name + "\": " + string(value)
+	}
+	_, err := io.WriteString(w, content+"\n}\n")
+	return err
+}
+
+func routines(list []common.Routine) []routine {
+	result := []routine{}
+	for _, r := range list {
+		item := routine{Name: r.Name, Type: r.Type, Returns: r.Returns.String, Comment: r.Comment, Body: nullString(r.Body)}
+		for _, p := range r.Parameters {
+			item.Parameters = append(item.Parameters, parameter(p))
+		}
+		result = append(result, item)
+	}
+	return result
+}
+
+func triggers(list []common.Trigger) []trigger {
+	result := []trigger{}
+	for _, t := range list {
+		result = append(result, trigger(t))
+	}
+	return result
+}
+
+func events(list []common.Event) []event {
+	result := []event{}
+	for _, e := range list {
+		result = append(result, event{Name: e.Name, Schedule: e.Schedule, Status: e.Status, Comment: e.Comment, Body: nullString(e.Body)})
+	}
+	return result
+}
+
+func lineage(list []common.LineageEdge) []lineageEdge {
+	result := []lineageEdge{}
+	for _, edge := range list {
+		result = append(result, lineageEdge(edge))
+	}
+	return result
+}
+
+func clusters(list []common.Cluster) []cluster {
+	result := []cluster{}
+	for _, c := range list {
+		result = append(result, cluster(c))
+	}
+	return result
+}